@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
 	"log"
 	"net/http"
 	"os"
+	"time"
+	_ "time/tzdata" // image alpine tidak punya zoneinfo
 
+	"github.com/jackyansen22/crud-category/internal/auth"
 	"github.com/jackyansen22/crud-category/internal/config"
 	"github.com/jackyansen22/crud-category/internal/database"
 	"github.com/jackyansen22/crud-category/internal/handler"
+	"github.com/jackyansen22/crud-category/internal/migration"
+	"github.com/jackyansen22/crud-category/internal/model"
 	"github.com/jackyansen22/crud-category/internal/repository"
 	"github.com/jackyansen22/crud-category/internal/service"
 )
@@ -27,6 +36,30 @@ func main() {
 	// ===== CONFIG =====
 	cfg := config.Load()
 
+	// ===== AUTH (JWT) =====
+	jwtSecret := cfg.JWTSecret
+	if jwtSecret == "" {
+		log.Println("⚠️ JWT_SECRET not set, using random secret (tokens invalid after restart)")
+		jwtSecret = randomSecret()
+	}
+	tokens := auth.NewTokenManager(jwtSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+
+	// ===== BUSINESS DAY (report) =====
+	businessLoc, err := time.LoadLocation(cfg.BusinessTimezone)
+	if err != nil {
+		log.Fatal("❌ invalid BUSINESS_TIMEZONE: ", err)
+	}
+	if cfg.BusinessDayCutoff < 0 || cfg.BusinessDayCutoff >= 24*time.Hour {
+		log.Fatal("❌ invalid BUSINESS_DAY_CUTOFF (0h-23h59m): ", cfg.BusinessDayCutoff)
+	}
+
+	// role per route: read = GET/HEAD, write = method lain (admin selalu boleh)
+	var (
+		cashierUp = []string{model.RoleCashier, model.RoleManager}
+		managerUp = []string{model.RoleManager}
+		adminOnly = []string{}
+	)
+
 	// ===== ROUTE ROOT =====
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -54,43 +87,141 @@ func main() {
 	} else {
 		log.Println("✅ Connected to database")
 
+		// ===== MIGRATION (MIGRATE_ON_START=true) =====
+		if cfg.MigrateOnStart {
+			runMigrations(db)
+		}
+
+		// Auth & users
+		userRepo := repository.NewUserRepository(db)
+		authService := service.NewAuthService(userRepo, tokens)
+		authHandler := handler.NewAuthHandler(authService)
+
+		created, err := authService.EnsureAdmin(context.Background(), cfg.AdminUsername, cfg.AdminPassword)
+		if err != nil {
+			log.Println("⚠️ create initial admin failed:", err)
+		} else if created {
+			log.Println("✅ Initial admin created:", cfg.AdminUsername)
+		}
+
+		http.HandleFunc("/auth/login", authHandler.Login)
+		http.HandleFunc("/auth/refresh", authHandler.Refresh)
+		http.HandleFunc("/users", handler.RequireRoles(adminOnly, adminOnly, authHandler.Users))
+
 		repo := repository.NewCategoryRepository(db)
-		svc := service.NewCategoryService(repo)
+		svc := service.NewCategoryService(repo, cfg.SoftDeleteRetention)
 		h := handler.NewCategoryHandler(svc)
 
-		http.HandleFunc("/categories", h.Categories)
-		http.HandleFunc("/categories/", h.CategoryByID)
+		http.HandleFunc("/categories", handler.RequireRoles(managerUp, managerUp, h.Categories))
+		http.HandleFunc("/categories/tree", handler.RequireRoles(managerUp, managerUp, h.Tree))
+		http.HandleFunc("/categories/", handler.RequireRoles(managerUp, managerUp, h.CategoryByID))
+
+		// Stock ledger
+		stockRepo := repository.NewStockRepository(db)
+		stockSvc := service.NewStockService(stockRepo)
+		stockHandler := handler.NewStockHandler(stockSvc)
+
+		http.HandleFunc("/stock/consistency", handler.RequireRoles(managerUp, managerUp, stockHandler.Consistency))
+
+		// Outlets & stock transfer antar outlet
+		outletRepo := repository.NewOutletRepository(db)
+		outletSvc := service.NewOutletService(outletRepo)
+		outletHandler := handler.NewOutletHandler(outletSvc)
+
+		http.HandleFunc("/outlets", handler.RequireRoles(cashierUp, managerUp, outletHandler.Outlets))
+		http.HandleFunc("/outlets/", handler.RequireRoles(cashierUp, managerUp, outletHandler.OutletByID))
+
+		transferRepo := repository.NewTransferRepository(db)
+		transferSvc := service.NewTransferService(transferRepo)
+		transferHandler := handler.NewTransferHandler(transferSvc)
+
+		http.HandleFunc("/transfers", handler.RequireRoles(managerUp, managerUp, transferHandler.Transfers))
+		http.HandleFunc("/transfers/", handler.RequireRoles(managerUp, managerUp, transferHandler.TransferByID))
+
+		// Suppliers & purchase order (penerimaan barang)
+		supplierRepo := repository.NewSupplierRepository(db)
+		supplierSvc := service.NewSupplierService(supplierRepo)
+		supplierHandler := handler.NewSupplierHandler(supplierSvc)
+
+		http.HandleFunc("/suppliers", handler.RequireRoles(managerUp, managerUp, supplierHandler.Suppliers))
+		http.HandleFunc("/suppliers/", handler.RequireRoles(managerUp, managerUp, supplierHandler.SupplierByID))
+
+		purchaseOrderRepo := repository.NewPurchaseOrderRepository(db)
+		purchaseOrderSvc := service.NewPurchaseOrderService(purchaseOrderRepo)
+		purchaseOrderHandler := handler.NewPurchaseOrderHandler(purchaseOrderSvc)
+
+		http.HandleFunc("/purchase-orders", handler.RequireRoles(managerUp, managerUp, purchaseOrderHandler.PurchaseOrders))
+		http.HandleFunc("/purchase-orders/", handler.RequireRoles(managerUp, managerUp, purchaseOrderHandler.PurchaseOrderByID))
+
+		// Product variants (/product/{id}/variants)
+		variantRepo := repository.NewVariantRepository(db)
+		variantSvc := service.NewVariantService(variantRepo)
+		variantHandler := handler.NewVariantHandler(variantSvc)
 
 		productRepo := repository.NewProductRepository(db)
-		productSvc := service.NewProductService(productRepo)
-		productHandler := handler.NewProductHandler(productSvc)
+		productSvc := service.NewProductService(productRepo, cfg.SoftDeleteRetention)
+		productHandler := handler.NewProductHandler(productSvc, stockHandler, variantHandler)
+
+		// hard delete product / category yang sudah lewat retention
+		// (produk dulu, category baru bisa di-purge kalau produknya sudah hilang;
+		// purge produk gagal tidak menghentikan purge category)
+		go func() {
+			for range time.Tick(time.Hour) {
+				products, err := productSvc.PurgeDeleted(context.Background())
+				if err != nil {
+					log.Println("⚠️ purge deleted products failed:", err)
+				}
+				categories, err := svc.PurgeDeleted(context.Background())
+				if err != nil {
+					log.Println("⚠️ purge deleted categories failed:", err)
+					continue
+				}
+				if products > 0 || categories > 0 {
+					log.Println("🧹 purged deleted products / categories:", products, categories)
+				}
+			}
+		}()
 
 		//http.HandleFunc("/api/produk", productHandler.Products)
 		//http.HandleFunc("/api/produk/", productHandler.ProductByID)
-		http.HandleFunc("/product", productHandler.Products)
-		http.HandleFunc("/product/", productHandler.ProductByID)
+		http.HandleFunc("/product", handler.RequireRoles(cashierUp, managerUp, productHandler.Products))
+		http.HandleFunc("/product/", handler.RequireRoles(cashierUp, managerUp, productHandler.ProductByID))
 
 		// =====================
 		// Transaction (Checkout)
 		// =====================
 		transactionRepo := repository.NewTransactionRepository(db)
-		transactionService := service.NewTransactionService(transactionRepo)
+		transactionService := service.NewTransactionService(transactionRepo, cfg.IdempotencyTTL, businessLoc, cfg.BusinessDayCutoff)
 		transactionHandler := handler.NewTransactionHandler(transactionService)
 
+		// bersihkan Idempotency-Key yang sudah expired
+		go func() {
+			for range time.Tick(time.Hour) {
+				n, err := transactionService.PurgeExpiredIdempotencyKeys(context.Background())
+				if err != nil {
+					log.Println("⚠️ purge idempotency keys failed:", err)
+					continue
+				}
+				if n > 0 {
+					log.Println("🧹 purged expired idempotency keys:", n)
+				}
+			}
+		}()
+
 		// POST /api/checkout
-		http.HandleFunc("/checkout", transactionHandler.Checkout)
+		http.HandleFunc("/checkout", handler.RequireRoles(cashierUp, cashierUp, transactionHandler.Checkout))
 
-		// Transactions (read only)
-		http.HandleFunc("/transactions", transactionHandler.GetAll)
-		http.HandleFunc("/transactions/", transactionHandler.GetByID)
+		// Transactions (read, refund, void)
+		http.HandleFunc("/transactions", handler.RequireRoles(managerUp, managerUp, transactionHandler.GetAll))
+		http.HandleFunc("/transactions/", handler.RequireRoles(managerUp, managerUp, transactionHandler.TransactionByID))
 
 		// Report
 		reportRepo := repository.NewReportRepository(db)
-		reportService := service.NewReportService(reportRepo)
+		reportService := service.NewReportService(reportRepo, businessLoc, cfg.BusinessDayCutoff)
 		reportHandler := handler.NewReportHandler(reportService)
 
-		http.HandleFunc("/report/hari-ini", reportHandler.Today)
-		http.HandleFunc("/report", reportHandler.ByRange)
+		http.HandleFunc("/report/hari-ini", handler.RequireRoles(managerUp, managerUp, reportHandler.Today))
+		http.HandleFunc("/report", handler.RequireRoles(managerUp, managerUp, reportHandler.ByRange))
 
 	}
 
@@ -99,7 +230,32 @@ func main() {
 	log.Fatal(
 		http.ListenAndServe(
 			":"+port,
-			handler.RecoverMiddleware(http.DefaultServeMux),
+			handler.RecoverMiddleware(
+				handler.AuthMiddleware(tokens, http.DefaultServeMux),
+			),
 		),
 	)
 }
+
+func runMigrations(db *sql.DB) {
+	m, err := migration.New(db)
+	if err != nil {
+		log.Fatal("❌ load migrations failed: ", err)
+	}
+
+	done, err := m.Up(context.Background())
+	for _, mg := range done {
+		log.Printf("✅ migrated %04d_%s", mg.Version, mg.Name)
+	}
+	if err != nil {
+		log.Fatal("❌ migration failed: ", err)
+	}
+}
+
+func randomSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal("❌ generate JWT secret failed: ", err)
+	}
+	return hex.EncodeToString(b)
+}
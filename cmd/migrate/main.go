@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/jackyansen22/crud-category/internal/config"
+	"github.com/jackyansen22/crud-category/internal/database"
+	"github.com/jackyansen22/crud-category/internal/migration"
+)
+
+const usage = `usage:
+  migrate up          jalankan semua migration pending
+  migrate down [n]    rollback n migration terakhir (default 1)
+  migrate status      tampilkan applied / pending`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+
+	db, err := database.Connect(cfg.DBUrl)
+	if err != nil {
+		log.Fatal("❌ DB connection failed: ", err)
+	}
+	defer db.Close()
+
+	m, err := migration.New(db)
+	if err != nil {
+		log.Fatal("❌ load migrations failed: ", err)
+	}
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+
+	case "up":
+		done, err := m.Up(ctx)
+		for _, mg := range done {
+			log.Printf("✅ up   %04d_%s", mg.Version, mg.Name)
+		}
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		if len(done) == 0 {
+			log.Println("✅ schema up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatal("❌ invalid step count: ", os.Args[2])
+			}
+		}
+
+		done, err := m.Down(ctx, steps)
+		for _, mg := range done {
+			log.Printf("✅ down %04d_%s", mg.Version, mg.Name)
+		}
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+
+		for _, st := range statuses {
+			state := "pending"
+			if st.Applied {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if st.Modified {
+				state += " (MODIFIED)"
+			}
+			fmt.Printf("%04d_%-30s %s\n", st.Version, st.Name, state)
+		}
+
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,127 @@
+package apperror
+
+import "net/http"
+
+// =====================================================
+// Kind: kategori error → HTTP status
+// =====================================================
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindUnprocessable
+	KindUnauthorized
+	KindForbidden
+	KindMethodNotAllowed
+	KindPreconditionFailed
+)
+
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// =====================================================
+// Error: domain error dengan code stabil untuk frontend
+// (mis. INSUFFICIENT_STOCK, CATEGORY_NOT_FOUND)
+// errors.Is membandingkan Code, jadi error dengan details
+// tetap cocok dengan sentinel-nya
+// =====================================================
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details any
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// copy dengan message / details lain, code & kind sama
+func (e *Error) WithMessage(message string) *Error {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+func (e *Error) WithDetails(details any) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Unprocessable(code, message string) *Error {
+	return New(KindUnprocessable, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func PreconditionFailed(code, message string) *Error {
+	return New(KindPreconditionFailed, code, message)
+}
+
+// =====================================================
+// error umum di level handler
+// =====================================================
+var (
+	ErrInternal         = New(KindInternal, "INTERNAL_ERROR", "internal server error")
+	ErrInvalidBody      = Validation("INVALID_BODY", "invalid request body")
+	ErrInvalidID        = Validation("INVALID_ID", "invalid id")
+	ErrInvalidQuery     = Validation("INVALID_QUERY", "invalid query parameter")
+	ErrNotFound         = NotFound("NOT_FOUND", "not found")
+	ErrMethodNotAllowed = New(KindMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	ErrUnauthorized     = Unauthorized("UNAUTHORIZED", "unauthorized")
+	ErrForbidden        = Forbidden("FORBIDDEN", "forbidden")
+
+	// If-Match tidak cocok dengan version sekarang (diubah request lain)
+	ErrPreconditionFailed = PreconditionFailed("PRECONDITION_FAILED", "resource has been modified, reload and try again")
+)
@@ -0,0 +1,26 @@
+package auth
+
+import "context"
+
+type contextKey struct{}
+
+func WithClaims(ctx context.Context, c *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// nil kalau request tidak terautentikasi
+func ClaimsFromContext(ctx context.Context) *Claims {
+	c, _ := ctx.Value(contextKey{}).(*Claims)
+	return c
+}
+
+// user id yang login, nil kalau tidak ada (job internal, migration, dll)
+func UserIDFromContext(ctx context.Context) *int {
+	c := ClaimsFromContext(ctx)
+	if c == nil {
+		return nil
+	}
+
+	id := c.UserID()
+	return &id
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackyansen22/crud-category/internal/apperror"
+)
+
+var ErrInvalidToken = apperror.Unauthorized("INVALID_TOKEN", "invalid or expired token")
+
+// jenis token (claim "typ")
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+type Claims struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Type     string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// user id disimpan di "sub"
+func (c *Claims) UserID() int {
+	id, _ := strconv.Atoi(c.Subject)
+	return id
+}
+
+// =====================================================
+// TOKEN MANAGER (HS256)
+// =====================================================
+type TokenManager struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenManager(secret string, accessTTL, refreshTTL time.Duration) *TokenManager {
+	return &TokenManager{
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+func (m *TokenManager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+// access + refresh token untuk user
+func (m *TokenManager) Issue(userID int, username, role string) (access, refresh string, err error) {
+	access, err = m.sign(userID, username, role, TokenAccess, m.accessTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	refresh, err = m.sign(userID, username, role, TokenRefresh, m.refreshTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	return access, refresh, nil
+}
+
+func (m *TokenManager) sign(userID int, username, role, typ string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Username: username,
+		Role:     role,
+		Type:     typ,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(userID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+}
+
+// parse + verifikasi signature, expiry, dan jenis token
+func (m *TokenManager) Parse(token, typ string) (*Claims, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	if err != nil || claims.Type != typ || claims.UserID() == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
@@ -1,10 +1,38 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
 	AppPort string
 	DBUrl   string
+
+	// berapa lama Idempotency-Key checkout disimpan (default 24h)
+	IdempotencyTTL time.Duration
+
+	// product / category soft delete di-hard delete setelah ini (default 30 hari)
+	SoftDeleteRetention time.Duration
+
+	// jalankan migration pending sebelum route didaftarkan
+	MigrateOnStart bool
+
+	// ===== REPORT =====
+	// timezone batas hari report (default Asia/Jakarta)
+	BusinessTimezone string
+	// hari bisnis mulai jam berapa, mis. 4h = 04:00 s/d 04:00 besoknya (default 0)
+	BusinessDayCutoff time.Duration
+
+	// ===== AUTH (JWT) =====
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// admin pertama, dibuat kalau tabel users masih kosong
+	AdminUsername string
+	AdminPassword string
 }
 
 func Load() *Config {
@@ -12,6 +40,13 @@ func Load() *Config {
 	viper.AutomaticEnv()
 	viper.ReadInConfig()
 
+	viper.SetDefault("IDEMPOTENCY_TTL", "24h")
+	viper.SetDefault("SOFT_DELETE_RETENTION", "720h")
+	viper.SetDefault("ACCESS_TOKEN_TTL", "15m")
+	viper.SetDefault("REFRESH_TOKEN_TTL", "168h")
+	viper.SetDefault("BUSINESS_TIMEZONE", "Asia/Jakarta")
+	viper.SetDefault("BUSINESS_DAY_CUTOFF", "0h")
+
 	return &Config{
 		AppPort: viper.GetString("APP_PORT"),
 		DBUrl: "postgres://" +
@@ -20,5 +55,18 @@ func Load() *Config {
 			viper.GetString("DB_HOST") + ":" +
 			viper.GetString("DB_PORT") + "/" +
 			viper.GetString("DB_NAME") + "?sslmode=require",
+		IdempotencyTTL:      viper.GetDuration("IDEMPOTENCY_TTL"),
+		SoftDeleteRetention: viper.GetDuration("SOFT_DELETE_RETENTION"),
+		MigrateOnStart:      viper.GetBool("MIGRATE_ON_START"),
+
+		BusinessTimezone:  viper.GetString("BUSINESS_TIMEZONE"),
+		BusinessDayCutoff: viper.GetDuration("BUSINESS_DAY_CUTOFF"),
+
+		JWTSecret:       viper.GetString("JWT_SECRET"),
+		AccessTokenTTL:  viper.GetDuration("ACCESS_TOKEN_TTL"),
+		RefreshTokenTTL: viper.GetDuration("REFRESH_TOKEN_TTL"),
+
+		AdminUsername: viper.GetString("ADMIN_USERNAME"),
+		AdminPassword: viper.GetString("ADMIN_PASSWORD"),
 	}
 }
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/service"
+)
+
+type AuthHandler struct {
+	service service.AuthService
+}
+
+func NewAuthHandler(service service.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// =====================================================
+// POST /auth/login
+// Body: { "username": "kasir1", "password": "..." }
+// =====================================================
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var req model.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperror.ErrInvalidBody)
+		return
+	}
+
+	tokens, err := h.service.Login(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// =====================================================
+// POST /auth/refresh
+// Body: { "refresh_token": "..." }
+// =====================================================
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var req model.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperror.ErrInvalidBody)
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// =====================================================
+// /users (admin)
+// GET  /users
+// POST /users  Body: { "username", "password", "role" }
+// =====================================================
+func (h *AuthHandler) Users(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+
+	case http.MethodGet:
+		users, err := h.service.GetUsers(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(users)
+
+	case http.MethodPost:
+		var req model.CreateUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+
+		u, err := h.service.CreateUser(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(u)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/jackyansen22/crud-category/internal/apperror"
 	"github.com/jackyansen22/crud-category/internal/model"
 	"github.com/jackyansen22/crud-category/internal/service"
+	"github.com/jackyansen22/crud-category/internal/validation"
 )
 
 type CategoryHandler struct {
@@ -20,6 +22,9 @@ func NewCategoryHandler(service service.CategoryService) *CategoryHandler {
 }
 
 // /categories
+// GET /categories?limit=20&sort=name&order=asc&cursor=
+// GET /categories?include_deleted=true (admin)
+// GET /categories + If-None-Match → 304 kalau hasil sama
 func (h *CategoryHandler) Categories(w http.ResponseWriter, r *http.Request) {
 	log.Println("🔥 CATEGORIES HANDLER HIT:", r.Method, r.URL.Path)
 
@@ -28,42 +33,110 @@ func (h *CategoryHandler) Categories(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 
 	case http.MethodGet:
-		categories, err := h.service.GetAll(r.Context())
+		page, err := parsePageRequest(r)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
-		json.NewEncoder(w).Encode(categories)
+
+		includeDeleted, err := parseIncludeDeleted(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		categories, err := h.service.GetAll(r.Context(), page, includeDeleted)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeListJSON(w, r, categories)
 
 	case http.MethodPost:
 		var c model.Category
 		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
-			http.Error(w, "invalid request body", http.StatusBadRequest)
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+
+		if err := validation.Category(&c); err != nil {
+			writeError(w, err)
 			return
 		}
 
 		if err := h.service.Create(r.Context(), &c); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 
 		log.Println("DEBUG CATEGORY:", c)
+		w.Header().Set("ETag", versionETag(c.Version))
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(c) // ❗ HARUS c
 
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// /categories/tree
+// /categories/tree?root_id=1
+// If-None-Match → 304 kalau tree tidak berubah
+func (h *CategoryHandler) Tree(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var rootID *int
+	if v := r.URL.Query().Get("root_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, apperror.ErrInvalidQuery.WithMessage("invalid root_id"))
+			return
+		}
+		rootID = &id
 	}
+
+	tree, err := h.service.GetTree(r.Context(), rootID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeListJSON(w, r, tree)
 }
 
 // /categories/{id}
+// /categories/{id}/ancestors
+// /categories/{id}/move
+// /categories/{id}/restore
+// DELETE /categories/{id}?policy=block|cascade|move&target_category_id=3
+// PUT /categories/{id} (tanpa parent_id = parent tetap, pindah parent lewat /move)
 func (h *CategoryHandler) CategoryByID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	idStr := strings.TrimPrefix(r.URL.Path, "/categories/")
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/categories/"), "/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		writeError(w, apperror.ErrInvalidID)
+		return
+	}
+
+	switch action {
+	case "":
+	case "ancestors":
+		h.ancestors(w, r, id)
+		return
+	case "move":
+		h.move(w, r, id)
+		return
+	case "restore":
+		h.restore(w, r, id)
+		return
+	default:
+		writeError(w, apperror.ErrNotFound)
 		return
 	}
 
@@ -72,33 +145,202 @@ func (h *CategoryHandler) CategoryByID(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		c, err := h.service.GetByID(r.Context(), id)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
+		w.Header().Set("ETag", versionETag(c.Version))
 		json.NewEncoder(w).Encode(c)
 
+	// PUT / PATCH / DELETE: If-Match: "3" → 412 kalau sudah diubah orang lain
+	// tanpa If-Match: PUT / DELETE tidak dicek (last write wins),
+	// PATCH dicek terhadap version yang dibaca saat merge
 	case http.MethodPut:
-		var c model.Category
-		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
-			http.Error(w, "invalid request body", http.StatusBadRequest)
+		version, err := parseIfMatch(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// parent_id tidak dikirim = parent lama (null = jadi root)
+		var body struct {
+			model.Category
+			ParentID json.RawMessage `json:"parent_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+
+		c := body.Category
+		if body.ParentID == nil {
+			current, err := h.service.GetByID(r.Context(), id)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			c.ParentID = current.ParentID
+		} else if err := json.Unmarshal(body.ParentID, &c.ParentID); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
 			return
 		}
 		c.ID = id
+		c.Version = version
+
+		if err := validation.Category(&c); err != nil {
+			writeError(w, err)
+			return
+		}
 
 		if err := h.service.Update(r.Context(), &c); err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
+		w.Header().Set("ETag", versionETag(c.Version))
+		json.NewEncoder(w).Encode(c)
+
+	// PATCH (JSON Merge Patch): "parent_id": null = jadi root,
+	// "description": null = kosong, "name": null ditolak
+	case http.MethodPatch:
+		version, err := parseIfMatch(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		current, err := h.service.GetByID(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		c, err := mergePatch(r, *current, "name")
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// tanpa If-Match tetap dikunci ke version yang di-merge
+		if version == 0 {
+			version = current.Version
+		}
+		c.ID = id
+		c.Version = version
+
+		if err := validation.Category(&c); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Update(r.Context(), &c); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("ETag", versionETag(c.Version))
 		json.NewEncoder(w).Encode(c)
 
 	case http.MethodDelete:
-		if err := h.service.Delete(r.Context(), id); err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
+		req, err := parseCategoryDeleteRequest(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if req.Version, err = parseIfMatch(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Delete(r.Context(), id, req); err != nil {
+			writeError(w, err)
 			return
 		}
 		w.WriteHeader(http.StatusNoContent)
 
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// GET /categories/{id}/ancestors (breadcrumb, root → parent)
+func (h *CategoryHandler) ancestors(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodGet {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	ancestors, err := h.service.GetAncestors(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if ancestors == nil {
+		ancestors = []model.Category{}
+	}
+	json.NewEncoder(w).Encode(ancestors)
+}
+
+// POST /categories/{id}/move
+// Body: { "parent_id": 2 }  (null = jadi root)
+func (h *CategoryHandler) move(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ParentID *int `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperror.ErrInvalidBody)
+		return
+	}
+
+	if err := h.service.Move(r.Context(), id, req.ParentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(c)
+}
+
+// POST /categories/{id}/restore
+func (h *CategoryHandler) restore(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	c, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(c)
+}
+
+func parseCategoryDeleteRequest(r *http.Request) (model.CategoryDeleteRequest, error) {
+	q := r.URL.Query()
+	req := model.CategoryDeleteRequest{Policy: model.CategoryDeleteBlock}
+
+	switch policy := model.CategoryDeletePolicy(q.Get("policy")); policy {
+	case "":
+	case model.CategoryDeleteBlock, model.CategoryDeleteCascade, model.CategoryDeleteMove:
+		req.Policy = policy
+	default:
+		return req, apperror.ErrInvalidQuery.WithMessage("invalid policy (block/cascade/move)")
 	}
+
+	if v := q.Get("target_category_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return req, apperror.ErrInvalidQuery.WithMessage("invalid target_category_id")
+		}
+		req.TargetCategoryID = &id
+	}
+
+	return req, nil
 }
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+)
+
+// =====================================================
+// ETag / If-Match (optimistic concurrency)
+// - detail: ETag = version row, mis. "7"
+// - PUT/PATCH/DELETE + If-Match beda version → 412
+// - If-Match optional: tanpa header = tidak dicek (client lama tetap jalan),
+// kecuali PATCH yang selalu dicek ke version saat merge
+// =====================================================
+var ErrInvalidIfMatch = apperror.Validation("INVALID_IF_MATCH", "If-Match must contain a single ETag")
+
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// If-Match → version yang diharapkan (0 = tidak ada / "*")
+func parseIfMatch(r *http.Request) (int, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	if strings.Contains(v, ",") {
+		return 0, ErrInvalidIfMatch
+	}
+
+	// If-Match pakai strong comparison: weak / ETag asing tidak pernah cocok
+	tag, ok := strings.CutPrefix(v, `"`)
+	if ok {
+		tag, ok = strings.CutSuffix(tag, `"`)
+	}
+	version, err := strconv.Atoi(tag)
+	if !ok || err != nil || version < 1 {
+		return 0, apperror.ErrPreconditionFailed
+	}
+	return version, nil
+}
+
+// =====================================================
+// List + If-None-Match
+// ETag = hash body JSON, sama → 304 tanpa body
+// =====================================================
+func writeListJSON(w http.ResponseWriter, r *http.Request, v any) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// If-None-Match pakai weak comparison (prefix W/ diabaikan)
+func etagMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
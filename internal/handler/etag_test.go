@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr error
+	}{
+		{"tidak ada", "", 0, nil},
+		{"wildcard", "*", 0, nil},
+		{"quoted", `"3"`, 3, nil},
+		{"quoted + spasi", ` "12" `, 12, nil},
+		{"weak tidak pernah cocok", `W/"3"`, 0, apperror.ErrPreconditionFailed},
+		{"tanpa kutip", "3", 0, apperror.ErrPreconditionFailed},
+		{"kutip tidak ditutup", `"3`, 0, apperror.ErrPreconditionFailed},
+		{"bukan angka", `"abc"`, 0, apperror.ErrPreconditionFailed},
+		{"version nol", `"0"`, 0, apperror.ErrPreconditionFailed},
+		{"lebih dari satu", `"3", "4"`, 0, ErrInvalidIfMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/product/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := parseIfMatch(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("version = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteListJSONNotModified(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeListJSON(w, httptest.NewRequest("GET", "/categories", nil), []int{1, 2})
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || etag == "" {
+		t.Fatalf("first response = %d, etag %q", w.Code, etag)
+	}
+
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{etag, 304},
+		{"W/" + etag, 304},
+		{`"other", ` + etag, 304},
+		{"*", 304},
+		{`"other"`, 200},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/categories", nil)
+		r.Header.Set("If-None-Match", tt.header)
+		w := httptest.NewRecorder()
+		writeListJSON(w, r, []int{1, 2})
+
+		if w.Code != tt.want {
+			t.Errorf("If-None-Match %s: status = %d, want %d", tt.header, w.Code, tt.want)
+		}
+		if w.Code == 304 && w.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: 304 with body %q", tt.header, w.Body.String())
+		}
+	}
+}
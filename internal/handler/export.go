@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/xuri/excelize/v2"
+)
+
+// =====================================================
+// Export CSV / XLSX
+// format via ?format=json|csv|xlsx atau header Accept
+// (?format= menang kalau dua-duanya ada)
+// =====================================================
+const (
+	exportJSON = "json"
+	exportCSV  = "csv"
+	exportXLSX = "xlsx"
+
+	mimeCSV  = "text/csv"
+	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+func parseExportFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case exportJSON, exportCSV, exportXLSX:
+		return format, nil
+	case "":
+	default:
+		return "", apperror.ErrInvalidQuery.WithMessage("invalid format (json/csv/xlsx)")
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, mimeCSV):
+		return exportCSV, nil
+	case strings.Contains(accept, mimeXLSX):
+		return exportXLSX, nil
+	}
+	return exportJSON, nil
+}
+
+// baris ditulis satu per satu (streaming), tidak ditampung dulu
+type exportWriter interface {
+	// tabel baru: xlsx = sheet baru, csv = dipisah baris kosong + judul
+	Sheet(name string, header ...string) error
+	Row(values ...any) error
+	Close() error
+
+	// true kalau sudah ada byte yang terkirim ke client
+	Started() bool
+}
+
+func newExportWriter(w http.ResponseWriter, format, filename string) exportWriter {
+	out := &exportResponse{w: w, format: format, filename: filename + "." + format}
+	if format == exportXLSX {
+		return &xlsxExport{exportResponse: out, file: excelize.NewFile()}
+	}
+	return &csvExport{exportResponse: out, csv: csv.NewWriter(out)}
+}
+
+// error sebelum response jalan masih bisa jadi JSON error,
+// setelahnya status sudah 200 jadi cukup di-log
+func writeExportError(w http.ResponseWriter, ew exportWriter, err error) {
+	if ew.Started() {
+		log.Println("❌ EXPORT FAILED:", err)
+		return
+	}
+	writeError(w, err)
+}
+
+// header response di-set saat byte pertama ditulis
+type exportResponse struct {
+	w        http.ResponseWriter
+	format   string
+	filename string
+	wrote    bool
+}
+
+func (o *exportResponse) Write(p []byte) (int, error) {
+	if !o.wrote {
+		o.wrote = true
+
+		contentType := mimeCSV + "; charset=utf-8"
+		if o.format == exportXLSX {
+			contentType = mimeXLSX
+		}
+		o.w.Header().Set("Content-Type", contentType)
+		o.w.Header().Set("Content-Disposition", `attachment; filename="`+o.filename+`"`)
+		o.w.WriteHeader(http.StatusOK)
+	}
+	return o.w.Write(p)
+}
+
+func (o *exportResponse) Started() bool {
+	return o.wrote
+}
+
+// =====================================================
+// CSV
+// =====================================================
+type csvExport struct {
+	*exportResponse
+	csv    *csv.Writer
+	sheets int
+}
+
+func (e *csvExport) Sheet(name string, header ...string) error {
+	if e.sheets > 0 {
+		if err := e.csv.Write([]string{}); err != nil {
+			return err
+		}
+		if err := e.csv.Write([]string{name}); err != nil {
+			return err
+		}
+	}
+	e.sheets++
+
+	return e.csv.Write(header)
+}
+
+func (e *csvExport) Row(values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = csvValue(exportValue(v))
+	}
+	return e.csv.Write(record)
+}
+
+func (e *csvExport) Close() error {
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		// cegah formula injection saat dibuka di spreadsheet
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// =====================================================
+// XLSX (excelize StreamWriter, baris besar di-spill ke temp file)
+// =====================================================
+type xlsxExport struct {
+	*exportResponse
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func (e *xlsxExport) Sheet(name string, header ...string) error {
+	if err := e.flush(); err != nil {
+		return err
+	}
+
+	// workbook baru selalu punya "Sheet1"
+	if e.stream == nil {
+		if err := e.file.SetSheetName("Sheet1", name); err != nil {
+			return err
+		}
+	} else if _, err := e.file.NewSheet(name); err != nil {
+		return err
+	}
+
+	stream, err := e.file.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+	e.stream, e.row = stream, 0
+
+	values := make([]any, len(header))
+	for i, h := range header {
+		values[i] = h
+	}
+	return e.Row(values...)
+}
+
+func (e *xlsxExport) Row(values ...any) error {
+	for i, v := range values {
+		v = exportValue(v)
+
+		// excel tidak kenal timezone: tulis jam dinding apa adanya
+		if t, ok := v.(time.Time); ok {
+			v = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+		}
+		values[i] = v
+	}
+
+	e.row++
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	return e.stream.SetRow(cell, values)
+}
+
+func (e *xlsxExport) Close() error {
+	defer e.file.Close()
+
+	if err := e.flush(); err != nil {
+		return err
+	}
+	return e.file.Write(e.exportResponse)
+}
+
+func (e *xlsxExport) flush() error {
+	if e.stream == nil {
+		return nil
+	}
+	return e.stream.Flush()
+}
+
+// pointer nil = cell kosong
+func exportValue(v any) any {
+	switch v := v.(type) {
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return v
+}
+
+// attributes varian jadi satu kolom, mis. "size=L warna=merah"
+func formatAttributes(attributes map[string]string) string {
+	parts := make([]string, 0, len(attributes))
+	for _, k := range slices.Sorted(maps.Keys(attributes)) {
+		parts = append(parts, k+"="+attributes[k])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/validation"
+)
+
+// =====================================================
+// JSON Merge Patch (RFC 7386) untuk PATCH
+// - field yang tidak dikirim = nilai lama
+// - null = field dihapus dari dokumen (pointer/slice jadi nil),
+// artinya per field beda: lihat komentar PATCH tiap resource
+// - null pada field di notNull ditolak (400 per field), supaya
+// tidak diam-diam jadi zero value (mis. stok 0)
+// - object di-merge rekursif, array & nilai lain diganti utuh
+// =====================================================
+const mimeMergePatch = "application/merge-patch+json"
+
+func mergePatch[T any](r *http.Request, current T, notNull ...string) (T, error) {
+	var zero T
+
+	// application/json juga diterima supaya client lama tidak repot
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, _ := mime.ParseMediaType(ct)
+		if mediaType != mimeMergePatch && mediaType != "application/json" {
+			return zero, apperror.ErrInvalidBody.WithMessage("Content-Type must be " + mimeMergePatch)
+		}
+	}
+
+	var patch map[string]any
+	if err := decodeJSONNumber(r.Body, &patch); err != nil || patch == nil {
+		return zero, apperror.ErrInvalidBody
+	}
+
+	var v validation.Validator
+	for _, field := range notNull {
+		value, ok := patch[field]
+		v.Check(!ok || value != nil, field, "cannot be null")
+	}
+	if err := v.Err(); err != nil {
+		return zero, err
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return zero, err
+	}
+	var target map[string]any
+	if err := decodeJSONNumber(bytes.NewReader(doc), &target); err != nil {
+		return zero, err
+	}
+
+	merged, err := json.Marshal(mergeValue(target, patch))
+	if err != nil {
+		return zero, err
+	}
+
+	var out T
+	if err := json.Unmarshal(merged, &out); err != nil {
+		return zero, apperror.ErrInvalidBody
+	}
+	return out, nil
+}
+
+func mergeValue(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any, len(p))
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergeValue(t[k], v)
+	}
+	return t
+}
+
+// angka tetap json.Number supaya integer besar tidak jadi float
+func decodeJSONNumber(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return dec.Decode(v)
+}
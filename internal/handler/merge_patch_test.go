@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/validation"
+)
+
+func TestMergeValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{"field tidak dikirim tetap", `{"a":1,"b":2}`, `{"a":3}`, `{"a":3,"b":2}`},
+		{"null menghapus field", `{"a":1,"b":2}`, `{"a":null}`, `{"b":2}`},
+		{"null untuk field yang tidak ada", `{"a":1}`, `{"x":null}`, `{"a":1}`},
+		{"field baru", `{"a":1}`, `{"b":2}`, `{"a":1,"b":2}`},
+		{"object di-merge rekursif", `{"o":{"x":1,"y":2}}`, `{"o":{"y":3,"z":4}}`, `{"o":{"x":1,"y":3,"z":4}}`},
+		{"null di object nested", `{"o":{"x":1,"y":2}}`, `{"o":{"x":null}}`, `{"o":{"y":2}}`},
+		{"object menggantikan non-object", `{"o":5}`, `{"o":{"x":1}}`, `{"o":{"x":1}}`},
+		{"array diganti utuh", `{"l":[1,2,3]}`, `{"l":[4]}`, `{"l":[4]}`},
+		{"array berisi object tidak di-merge", `{"l":[{"x":1}]}`, `{"l":[{"y":2}]}`, `{"l":[{"y":2}]}`},
+		{"patch kosong", `{"a":1}`, `{}`, `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target, patch, want any
+			for _, x := range []struct {
+				s string
+				v *any
+			}{{tt.target, &target}, {tt.patch, &patch}, {tt.want, &want}} {
+				if err := json.Unmarshal([]byte(x.s), x.v); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := mergeValue(target, patch); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+type patchDoc struct {
+	Nama   string            `json:"nama"`
+	Stok   int               `json:"stok"`
+	Note   *string           `json:"note"`
+	Tags   []string          `json:"tags"`
+	Attrs  map[string]string `json:"attrs"`
+	BigNum int64             `json:"big_num"`
+}
+
+func TestMergePatch(t *testing.T) {
+	note := "lama"
+	current := patchDoc{
+		Nama:   "Indomie",
+		Stok:   12,
+		Note:   &note,
+		Tags:   []string{"a", "b"},
+		Attrs:  map[string]string{"size": "L", "warna": "merah"},
+		BigNum: 1 << 60,
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        patchDoc
+		wantErr     error
+		wantField   string // field di details validation
+	}{
+		{
+			name:        "field tidak dikirim tetap",
+			contentType: mimeMergePatch,
+			body:        `{"nama":"Mie Sedaap"}`,
+			want:        patchDoc{Nama: "Mie Sedaap", Stok: 12, Note: &note, Tags: []string{"a", "b"}, Attrs: current.Attrs, BigNum: 1 << 60},
+		},
+		{
+			name:        "null pointer jadi nil",
+			contentType: "application/json",
+			body:        `{"note":null}`,
+			want:        patchDoc{Nama: "Indomie", Stok: 12, Tags: []string{"a", "b"}, Attrs: current.Attrs, BigNum: 1 << 60},
+		},
+		{
+			name: "object nested di-merge, array diganti",
+			body: `{"attrs":{"warna":"biru","size":null},"tags":["c"]}`,
+			want: patchDoc{Nama: "Indomie", Stok: 12, Note: &note, Tags: []string{"c"}, Attrs: map[string]string{"warna": "biru"}, BigNum: 1 << 60},
+		},
+		{
+			name:      "null pada field notNull ditolak",
+			body:      `{"stok":null}`,
+			wantErr:   validation.ErrValidationFailed,
+			wantField: "stok",
+		},
+		{
+			name:    "tipe salah",
+			body:    `{"stok":"banyak"}`,
+			wantErr: apperror.ErrInvalidBody,
+		},
+		{
+			name:    "body bukan object",
+			body:    `[{"stok":1}]`,
+			wantErr: apperror.ErrInvalidBody,
+		},
+		{
+			name:    "body null",
+			body:    `null`,
+			wantErr: apperror.ErrInvalidBody,
+		},
+		{
+			name:        "content type lain",
+			contentType: "text/plain",
+			body:        `{"nama":"x"}`,
+			wantErr:     apperror.ErrInvalidBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PATCH", "/product/1", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			got, err := mergePatch(r, current, "nama", "stok")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantField != "" {
+				var appErr *apperror.Error
+				if !errors.As(err, &appErr) || !strings.Contains(toJSON(t, appErr.Details), `"`+tt.wantField+`"`) {
+					t.Errorf("details = %v, want field %q", err, tt.wantField)
+				}
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// current tidak ikut berubah
+	if current.Stok != 12 || current.Attrs["size"] != "L" {
+		t.Errorf("current modified: %+v", current)
+	}
+}
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -3,6 +3,12 @@ package handler
 import (
 	"log"
 	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/auth"
+	"github.com/jackyansen22/crud-category/internal/model"
 )
 
 func RecoverMiddleware(next http.Handler) http.Handler {
@@ -10,9 +16,69 @@ func RecoverMiddleware(next http.Handler) http.Handler {
 		defer func() {
 			if err := recover(); err != nil {
 				log.Println("PANIC RECOVERED:", err)
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				writeError(w, apperror.ErrInternal)
 			}
 		}()
 		next.ServeHTTP(w, r)
 	})
 }
+
+// path yang bisa diakses tanpa token
+var publicPaths = []string{"/", "/health", "/auth/login", "/auth/refresh"}
+
+// =====================================================
+// AUTH: Authorization: Bearer <access token>
+// claims disimpan di context (auth.ClaimsFromContext)
+// =====================================================
+func AuthMiddleware(tokens *auth.TokenManager, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(publicPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, apperror.ErrUnauthorized.WithMessage("missing bearer token"))
+			return
+		}
+
+		claims, err := tokens.Parse(token, auth.TokenAccess)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+	})
+}
+
+// =====================================================
+// RBAC per route
+// - read : role untuk GET / HEAD
+// - write: role untuk method lain
+// admin selalu boleh
+// =====================================================
+func RequireRoles(read, write []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims := auth.ClaimsFromContext(r.Context())
+		if claims == nil {
+			writeError(w, apperror.ErrUnauthorized)
+			return
+		}
+
+		allowed := write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			allowed = read
+		}
+
+		if claims.Role != model.RoleAdmin && !slices.Contains(allowed, claims.Role) {
+			writeError(w, apperror.ErrForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
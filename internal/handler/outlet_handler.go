@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/service"
+	"github.com/jackyansen22/crud-category/internal/validation"
+)
+
+type OutletHandler struct {
+	service service.OutletService
+}
+
+func NewOutletHandler(service service.OutletService) *OutletHandler {
+	return &OutletHandler{service: service}
+}
+
+// =====================================================
+// /outlets
+// GET  /outlets
+// POST /outlets  Body: { "name": "Cabang Depok", "address": "..." }
+// =====================================================
+func (h *OutletHandler) Outlets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+
+	case http.MethodGet:
+		outlets, err := h.service.GetAll(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(outlets)
+
+	case http.MethodPost:
+		var o model.Outlet
+		if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+
+		if err := validation.Outlet(&o); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Create(r.Context(), &o); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(o)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// =====================================================
+// /outlets/{id}
+// GET /outlets/{id}
+// PUT /outlets/{id}  Body: { "name": "...", "address": "...", "active": true, "is_default": false }
+// GET /outlets/{id}/stock
+// =====================================================
+func (h *OutletHandler) OutletByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/outlets/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, apperror.ErrInvalidID.WithMessage("invalid outlet id"))
+		return
+	}
+
+	switch action {
+	case "":
+	case "stock":
+		h.stock(w, r, id)
+		return
+	default:
+		writeError(w, apperror.ErrNotFound)
+		return
+	}
+
+	switch r.Method {
+
+	case http.MethodGet:
+		o, err := h.service.GetByID(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(o)
+
+	case http.MethodPut:
+		var o model.Outlet
+		if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+		o.ID = id
+
+		if err := validation.Outlet(&o); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Update(r.Context(), &o); err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(o)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// GET /outlets/{id}/stock
+func (h *OutletHandler) stock(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodGet {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	stock, err := h.service.GetStock(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(stock)
+}
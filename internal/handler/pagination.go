@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/auth"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// =====================================================
+// ?limit=&offset=&cursor=&sort=&order=asc|desc
+// sort divalidasi di repository (whitelist per resource)
+// =====================================================
+func parsePageRequest(r *http.Request) (model.PageRequest, error) {
+	q := r.URL.Query()
+	page := model.PageRequest{
+		Limit:  defaultPageLimit,
+		Cursor: q.Get("cursor"),
+		Sort:   q.Get("sort"),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return page, apperror.ErrInvalidQuery.WithMessage("invalid limit (1-" + strconv.Itoa(maxPageLimit) + ")")
+		}
+		page.Limit = n
+	}
+
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return page, apperror.ErrInvalidQuery.WithMessage("invalid offset")
+		}
+		page.Offset = n
+	}
+
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		page.Desc = true
+	default:
+		return page, apperror.ErrInvalidQuery.WithMessage("invalid order (asc/desc)")
+	}
+
+	return page, nil
+}
+
+// ?include_deleted=true: ikutkan row soft delete (admin only)
+func parseIncludeDeleted(r *http.Request) (bool, error) {
+	v, err := parseBoolParam(r.URL.Query(), "include_deleted")
+	if err != nil || v == nil || !*v {
+		return false, err
+	}
+
+	if claims := auth.ClaimsFromContext(r.Context()); claims == nil || claims.Role != model.RoleAdmin {
+		return false, apperror.ErrForbidden.WithMessage("include_deleted is only available to admin")
+	}
+	return true, nil
+}
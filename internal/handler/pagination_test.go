@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+func TestParsePageRequest(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    model.PageRequest
+		wantErr bool
+	}{
+		{query: "", want: model.PageRequest{Limit: defaultPageLimit}},
+		{query: "limit=1", want: model.PageRequest{Limit: 1}},
+		{query: "limit=200", want: model.PageRequest{Limit: maxPageLimit}},
+		{query: "limit=0", wantErr: true},
+		{query: "limit=201", wantErr: true},
+		{query: "limit=-5", wantErr: true},
+		{query: "limit=abc", wantErr: true},
+		{query: "offset=40", want: model.PageRequest{Limit: defaultPageLimit, Offset: 40}},
+		{query: "offset=-1", wantErr: true},
+		{query: "sort=harga&order=desc", want: model.PageRequest{Limit: defaultPageLimit, Sort: "harga", Desc: true}},
+		{query: "order=random", wantErr: true},
+		{query: "cursor=abc", want: model.PageRequest{Limit: defaultPageLimit, Cursor: "abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/product?"+tt.query, nil)
+
+			got, err := parsePageRequest(r)
+			if tt.wantErr {
+				if !errors.Is(err, apperror.ErrInvalidQuery) {
+					t.Fatalf("err = %v, want INVALID_QUERY", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
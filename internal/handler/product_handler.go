@@ -2,26 +2,48 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/jackyansen22/crud-category/internal/apperror"
 	"github.com/jackyansen22/crud-category/internal/model"
 	"github.com/jackyansen22/crud-category/internal/service"
+	"github.com/jackyansen22/crud-category/internal/validation"
 )
 
 type ProductHandler struct {
-	service service.ProductService
+	service  service.ProductService
+	stock    *StockHandler
+	variants *VariantHandler
 }
 
-func NewProductHandler(service service.ProductService) *ProductHandler {
-	return &ProductHandler{service: service}
+func NewProductHandler(
+	service service.ProductService,
+	stock *StockHandler,
+	variants *VariantHandler,
+) *ProductHandler {
+	return &ProductHandler{service: service, stock: stock, variants: variants}
 }
 
+// PATCH /product/{id}: null di field ini ditolak (bukan reset ke 0 / false)
+var productNotNull = []string{"nama", "harga", "harga_pokok", "stok", "active", "category_id"}
+
 // =====================================================
 // /product
 // GET    /product
 // GET    /product?name=indomie&active=true
+// GET    /product?category_id=1,2&include_subcategories=true
+// GET    /product?min_harga=1000&max_harga=5000&min_stok=1&max_stok=10
+// GET    /product?in_stock=false  /  ?low_stock=true&low_stock_threshold=5
+// GET    /product?created_from=2025-01-01&updated_to=2025-01-31
+// GET    /product?limit=20&sort=harga&order=desc&cursor=
+// GET    /product?include_deleted=true (admin)
+// GET    /product?format=csv|xlsx (atau header Accept, filter sama, tanpa pagination)
+// GET    /product + If-None-Match → 304 kalau hasil sama
 // POST   /product
 // =====================================================
 func (h *ProductHandler) Products(w http.ResponseWriter, r *http.Request) {
@@ -33,36 +55,40 @@ func (h *ProductHandler) Products(w http.ResponseWriter, r *http.Request) {
 	// GET /product (with filter)
 	// -----------------------------
 	case http.MethodGet:
-		name := r.URL.Query().Get("name")
-
-		var active *bool
-		if v := r.URL.Query().Get("active"); v != "" {
-			b, err := strconv.ParseBool(v)
-			if err != nil {
-				http.Error(w, "invalid active value (true/false)", http.StatusBadRequest)
-				return
-			}
-			active = &b
+		filter, err := parseProductFilter(r.URL.Query())
+		if err != nil {
+			writeError(w, err)
+			return
 		}
 
-		// 🔍 FILTER MODE
-		if name != "" || active != nil {
-			products, err := h.service.Search(r.Context(), name, active)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			json.NewEncoder(w).Encode(products)
+		if filter.IncludeDeleted, err = parseIncludeDeleted(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		format, err := parseExportFormat(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if format != exportJSON {
+			h.export(w, r, format, filter)
+			return
+		}
+
+		page, err := parsePageRequest(r)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 
-		// 🔁 NORMAL GET ALL
-		products, err := h.service.GetAll(r.Context())
+		// 🔍 FILTER + PAGINATION (filter kosong = semua produk)
+		products, err := h.service.Search(r.Context(), filter, page)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
-		json.NewEncoder(w).Encode(products)
+		writeListJSON(w, r, products)
 
 	// -----------------------------
 	// POST /product
@@ -70,20 +96,99 @@ func (h *ProductHandler) Products(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		var p model.Product
 		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-			http.Error(w, "invalid request body", http.StatusBadRequest)
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+
+		if err := validation.Product(&p); err != nil {
+			writeError(w, err)
 			return
 		}
 
 		if err := h.service.Create(r.Context(), &p); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 
+		w.Header().Set("ETag", versionETag(p.Version))
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(p)
 
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// =====================================================
+// PATCH /product/bulk
+// PATCH /product/bulk?category_id=3&active=true (filter sama dengan GET /product)
+// Body: { "ids": [1, 2], "harga": { "percent": 10 }, "active": true, "category_id": 4 }
+// harga: { "set": 5000 } | { "add": -500 } | { "percent": 10 }
+// =====================================================
+func (h *ProductHandler) bulkUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var u model.ProductBulkUpdate
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		writeError(w, apperror.ErrInvalidBody)
+		return
+	}
+
+	// filter kosong (tidak ada kriteria) = tidak pakai filter
+	filter, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !filter.IsEmpty() {
+		u.Filter = &filter
+	}
+
+	if err := validation.ProductBulkUpdate(&u); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	result, err := h.service.BulkUpdate(r.Context(), u)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// =====================================================
+// export produk (streaming), timestamp dalam UTC
+// =====================================================
+func (h *ProductHandler) export(w http.ResponseWriter, r *http.Request, format string, filter model.ProductFilter) {
+	ew := newExportWriter(w, format, "products")
+
+	err := ew.Sheet("Produk",
+		"id", "sku", "nama", "category_id", "category", "harga", "harga_pokok", "stok", "active",
+		"barcodes", "created_at_utc", "updated_at_utc", "deleted_at_utc",
+	)
+	if err == nil {
+		err = h.service.Export(r.Context(), filter, func(p model.Product) error {
+			var deletedAt *time.Time
+			if p.DeletedAt != nil {
+				t := p.DeletedAt.UTC()
+				deletedAt = &t
+			}
+			return ew.Row(
+				p.ID, p.SKU, p.Nama, p.CategoryID, p.CategoryName, p.Harga, p.HargaPokok, p.Stok, p.Active,
+				strings.Join(p.Barcodes, " "), p.CreatedAt.UTC(), p.UpdatedAt.UTC(), deletedAt,
+			)
+		})
+	}
+	if err == nil {
+		err = ew.Close()
+	}
+	if err != nil {
+		writeExportError(w, ew, err)
 	}
 }
 
@@ -92,14 +197,65 @@ func (h *ProductHandler) Products(w http.ResponseWriter, r *http.Request) {
 // GET    /product/{id}
 // PUT    /product/{id}
 // DELETE /product/{id}
+// GET    /product/{id}/stock-history
+// POST   /product/{id}/stock
+// POST   /product/{id}/restore
+// GET    /product/{id}/cost-history
+// GET    /product/{id}/variants
+// POST   /product/{id}/variants
+// GET    /product/{id}/variants/{vid}
+// PUT    /product/{id}/variants/{vid}
+// DELETE /product/{id}/variants/{vid}
+// GET    /product/barcode/{code}
+// POST   /product/import
+// PATCH  /product/bulk
 // =====================================================
 func (h *ProductHandler) ProductByID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	idStr := strings.TrimPrefix(r.URL.Path, "/product/")
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/product/"), "/")
+	if idStr == "barcode" {
+		h.byBarcode(w, r, action)
+		return
+	}
+	if idStr == "import" && action == "" {
+		h.importProducts(w, r)
+		return
+	}
+	if idStr == "bulk" && action == "" {
+		h.bulkUpdate(w, r)
+		return
+	}
+
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "invalid product id", http.StatusBadRequest)
+		writeError(w, apperror.ErrInvalidID.WithMessage("invalid product id"))
+		return
+	}
+
+	switch action {
+	case "":
+	case "stock-history":
+		h.stock.History(w, r, id)
+		return
+	case "stock":
+		h.stock.Adjust(w, r, id)
+		return
+	case "restore":
+		h.restore(w, r, id)
+		return
+	case "cost-history":
+		h.costHistory(w, r, id)
+		return
+	case "variants":
+		h.variants.Variants(w, r, id)
+		return
+	default:
+		if vid, ok := strings.CutPrefix(action, "variants/"); ok {
+			h.variants.VariantByID(w, r, id, vid)
+			return
+		}
+		writeError(w, apperror.ErrNotFound)
 		return
 	}
 
@@ -111,39 +267,296 @@ func (h *ProductHandler) ProductByID(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		product, err := h.service.GetByID(r.Context(), id)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
+		w.Header().Set("ETag", versionETag(product.Version))
 		json.NewEncoder(w).Encode(product)
 
 	// -----------------------------
-	// PUT /product/{id}
+	// PUT /product/{id} (tanpa category_id = kategori lama)
+	// If-Match: "7" → 412 kalau sudah diubah orang lain
+	// tanpa If-Match = tidak dicek (last write wins), client sebaiknya selalu kirim
 	// -----------------------------
 	case http.MethodPut:
+		version, err := parseIfMatch(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
 		var p model.Product
 		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-			http.Error(w, "invalid request body", http.StatusBadRequest)
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+		p.ID = id
+		p.Version = version
+
+		if err := validation.Product(&p); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Update(r.Context(), &p); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("ETag", versionETag(p.Version))
+		json.NewEncoder(w).Encode(p)
+
+	// -----------------------------
+	// PATCH /product/{id} (JSON Merge Patch)
+	// Body: { "harga": 12000, "category_id": 3 }
+	// - field yang tidak dikirim tidak diubah
+	// - "barcodes": null = hapus semua barcode, "sku": null = hapus sku
+	// - null pada nama / harga / harga_pokok / stok / active / category_id ditolak
+	// If-Match: "7" → 412 kalau sudah diubah orang lain
+	// tanpa If-Match = dicek terhadap version yang dibaca saat merge
+	// -----------------------------
+	case http.MethodPatch:
+		version, err := parseIfMatch(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		current, err := h.service.GetByID(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		p, err := mergePatch(r, *current, productNotNull...)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
+
+		// tanpa If-Match tetap dikunci ke version yang di-merge, supaya
+		// perubahan di antara baca & tulis (mis. stok dari checkout) tidak tertimpa
+		if version == 0 {
+			version = current.Version
+		}
 		p.ID = id
+		p.Version = version
+		if p.Barcodes == nil {
+			p.Barcodes = []string{}
+		}
+
+		if err := validation.Product(&p); err != nil {
+			writeError(w, err)
+			return
+		}
 
 		if err := h.service.Update(r.Context(), &p); err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
+		w.Header().Set("ETag", versionETag(p.Version))
 		json.NewEncoder(w).Encode(p)
 
 	// -----------------------------
 	// DELETE /product/{id}
+	// If-Match optional, tanpa If-Match = tidak dicek
 	// -----------------------------
 	case http.MethodDelete:
-		if err := h.service.Delete(r.Context(), id); err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
+		version, err := parseIfMatch(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Delete(r.Context(), id, version); err != nil {
+			writeError(w, err)
 			return
 		}
 		w.WriteHeader(http.StatusNoContent)
 
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// GET /product/barcode/{code} (EAN-13 / UPC-A, untuk scanner kasir)
+func (h *ProductHandler) byBarcode(w http.ResponseWriter, r *http.Request, code string) {
+	if r.Method != http.MethodGet {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	p, err := h.service.GetByBarcode(r.Context(), code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(p)
+}
+
+// POST /product/{id}/restore
+func (h *ProductHandler) restore(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	p, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(p)
+}
+
+// GET /product/{id}/cost-history (perubahan HPP dari purchase order)
+func (h *ProductHandler) costHistory(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodGet {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	history, err := h.service.GetCostHistory(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(history)
+}
+
+const defaultLowStockThreshold = 5
+
+// =====================================================
+// PARSE + VALIDASI FILTER GET /product
+// =====================================================
+func parseProductFilter(q url.Values) (model.ProductFilter, error) {
+	f := model.ProductFilter{Name: q.Get("name")}
+	var err error
+
+	if f.Active, err = parseBoolParam(q, "active"); err != nil {
+		return f, err
+	}
+	if f.InStock, err = parseBoolParam(q, "in_stock"); err != nil {
+		return f, err
+	}
+
+	// category_id=1,2 atau category_id=1&category_id=2
+	for _, v := range q["category_id"] {
+		for _, part := range strings.Split(v, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || id <= 0 {
+				return f, apperror.ErrInvalidQuery.WithMessage(fmt.Sprintf("invalid category_id %q", part))
+			}
+			f.CategoryIDs = append(f.CategoryIDs, id)
+		}
+	}
+
+	sub, err := parseBoolParam(q, "include_subcategories")
+	if err != nil {
+		return f, err
+	}
+	f.IncludeSubcategories = sub != nil && *sub
+
+	for name, dst := range map[string]**int{
+		"min_harga": &f.MinHarga,
+		"max_harga": &f.MaxHarga,
+		"min_stok":  &f.MinStok,
+		"max_stok":  &f.MaxStok,
+	} {
+		if *dst, err = parseIntParam(q, name); err != nil {
+			return f, err
+		}
+	}
+
+	if f.MinHarga != nil && *f.MinHarga < 0 {
+		return f, apperror.ErrInvalidQuery.WithMessage("min_harga cannot be negative")
+	}
+	if f.MinHarga != nil && f.MaxHarga != nil && *f.MinHarga > *f.MaxHarga {
+		return f, apperror.ErrInvalidQuery.WithMessage("min_harga cannot be greater than max_harga")
+	}
+	if f.MinStok != nil && f.MaxStok != nil && *f.MinStok > *f.MaxStok {
+		return f, apperror.ErrInvalidQuery.WithMessage("min_stok cannot be greater than max_stok")
+	}
+
+	lowStock, err := parseBoolParam(q, "low_stock")
+	if err != nil {
+		return f, err
+	}
+	if lowStock != nil && *lowStock {
+		threshold, err := parseIntParam(q, "low_stock_threshold")
+		if err != nil {
+			return f, err
+		}
+		if threshold == nil {
+			t := defaultLowStockThreshold
+			threshold = &t
+		}
+		f.LowStockThreshold = threshold
+	}
+
+	for name, dst := range map[string]**time.Time{
+		"created_from": &f.CreatedFrom,
+		"created_to":   &f.CreatedTo,
+		"updated_from": &f.UpdatedFrom,
+		"updated_to":   &f.UpdatedTo,
+	} {
+		if *dst, err = parseTimeParam(q, name, strings.HasSuffix(name, "_to")); err != nil {
+			return f, err
+		}
+	}
+
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo) {
+		return f, apperror.ErrInvalidQuery.WithMessage("created_from must be before created_to")
+	}
+	if f.UpdatedFrom != nil && f.UpdatedTo != nil && !f.UpdatedFrom.Before(*f.UpdatedTo) {
+		return f, apperror.ErrInvalidQuery.WithMessage("updated_from must be before updated_to")
+	}
+
+	return f, nil
+}
+
+func parseBoolParam(q url.Values, name string) (*bool, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, apperror.ErrInvalidQuery.WithMessage(fmt.Sprintf("invalid %s value (true/false)", name))
+	}
+	return &b, nil
+}
+
+func parseIntParam(q url.Values, name string) (*int, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, apperror.ErrInvalidQuery.WithMessage(fmt.Sprintf("invalid %s", name))
+	}
+	return &n, nil
+}
+
+// YYYY-MM-DD atau RFC3339; tanggal saja untuk *_to = sampai akhir hari (exclusive)
+func parseTimeParam(q url.Values, name string, endOfDay bool) (*time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, apperror.ErrInvalidQuery.WithMessage(fmt.Sprintf("invalid %s (YYYY-MM-DD or RFC3339)", name))
+	}
+	if endOfDay {
+		t = t.Add(24 * time.Hour)
 	}
+	return &t, nil
 }
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+const maxImportBytes = 10 << 20 // 10 MB
+
+// kolom CSV import (sama dengan json tag model.ProductImportRow)
+var (
+	importColumns         = []string{"sku", "nama", "kategori", "harga", "harga_pokok", "stok", "active", "barcodes"}
+	importRequiredColumns = []string{"nama", "kategori", "harga"}
+)
+
+// =====================================================
+// POST /product/import
+// POST /product/import?dry_run=true
+// Content-Type: text/csv, baris pertama = header kolom:
+// sku,nama,kategori,harga,harga_pokok,stok,active,barcodes (barcodes dipisah spasi)
+// Content-Type: application/x-ndjson, satu object JSON per baris:
+// {"sku":"IDM-01","nama":"Indomie","kategori":"Mie","harga":3500,"stok":10}
+// - kolom kosong = default (produk baru) / tidak diubah (produk lama)
+// - dry_run: semua baris dicek, tidak ada yang disimpan
+// =====================================================
+func (h *ProductHandler) importProducts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var dryRun bool
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, apperror.ErrInvalidQuery.WithMessage("invalid dry_run (true/false)"))
+			return
+		}
+		dryRun = b
+	}
+
+	var (
+		body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+		rows []model.ProductImportRow
+		err  error
+	)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		rows, err = parseImportCSV(body)
+	case "application/x-ndjson", "application/jsonl":
+		rows, err = parseImportJSONLines(body)
+	default:
+		err = apperror.ErrInvalidBody.WithMessage("Content-Type must be text/csv or application/x-ndjson")
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	result, err := h.service.Import(r.Context(), rows, dryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func parseImportCSV(body io.Reader) ([]model.ProductImportRow, error) {
+	cr := csv.NewReader(body)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, importBodyError(err)
+	}
+
+	// BOM dari Excel
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	index := make(map[string]int, len(header))
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if !slices.Contains(importColumns, col) {
+			return nil, apperror.ErrInvalidBody.WithMessage("unknown column: " + col)
+		}
+		if _, ok := index[col]; ok {
+			return nil, apperror.ErrInvalidBody.WithMessage("duplicate column: " + col)
+		}
+		index[col] = i
+	}
+	for _, col := range importRequiredColumns {
+		if _, ok := index[col]; !ok {
+			return nil, apperror.ErrInvalidBody.WithMessage("missing column: " + col)
+		}
+	}
+
+	var rows []model.ProductImportRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := model.ProductImportRow{Line: line}
+
+		switch {
+		case errors.Is(err, csv.ErrFieldCount):
+			row.ParseError = "wrong number of columns"
+		case err != nil:
+			return nil, importBodyError(err)
+		default:
+			parseImportRecord(&row, record, index)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// error konversi pertama disimpan di row.ParseError
+func parseImportRecord(row *model.ProductImportRow, record []string, index map[string]int) {
+	get := func(col string) string {
+		if i, ok := index[col]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	fail := func(col string) {
+		if row.ParseError == "" {
+			row.ParseError = "invalid " + col
+		}
+	}
+	optionalInt := func(col string) *int {
+		v := get(col)
+		if v == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			fail(col)
+			return nil
+		}
+		return &n
+	}
+
+	row.SKU = get("sku")
+	row.Nama = get("nama")
+	row.Kategori = get("kategori")
+
+	harga, err := strconv.Atoi(get("harga"))
+	if err != nil {
+		fail("harga")
+	}
+	row.Harga = harga
+	row.HargaPokok = optionalInt("harga_pokok")
+	row.Stok = optionalInt("stok")
+
+	if v := get("active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			fail("active")
+		}
+		row.Active = &active
+	}
+
+	// kosong = barcode lama tetap (nil), bukan dihapus
+	if v := get("barcodes"); v != "" {
+		row.Barcodes = strings.Fields(v)
+	}
+}
+
+func parseImportJSONLines(body io.Reader) ([]model.ProductImportRow, error) {
+	sc := bufio.NewScanner(body)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var (
+		rows []model.ProductImportRow
+		line int
+	)
+	for sc.Scan() {
+		line++
+
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var row model.ProductImportRow
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&row); err != nil {
+			row = model.ProductImportRow{ParseError: "invalid JSON: " + err.Error()}
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, importBodyError(err)
+	}
+
+	return rows, nil
+}
+
+func importBodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apperror.ErrInvalidBody.WithMessage("import file too large (max 10 MB)")
+	}
+	return apperror.ErrInvalidBody.WithMessage("invalid import file: " + err.Error())
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/service"
+	"github.com/jackyansen22/crud-category/internal/validation"
+)
+
+type PurchaseOrderHandler struct {
+	service service.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(service service.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service}
+}
+
+// =====================================================
+// /purchase-orders
+// GET  /purchase-orders?status=ordered&supplier_id=1&limit=20&cursor=
+// POST /purchase-orders
+// Body: { "supplier_id": 1, "outlet_id": 2, "note": "...",
+// "items": [ { "product_id": 1, "quantity": 24, "unit_cost": 2500 } ] }
+// =====================================================
+func (h *PurchaseOrderHandler) PurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+
+	case http.MethodGet:
+		filter, err := parsePurchaseOrderFilter(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		page, err := parsePageRequest(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		orders, err := h.service.GetAll(r.Context(), filter, page)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(orders)
+
+	case http.MethodPost:
+		var po model.PurchaseOrder
+		if err := json.NewDecoder(r.Body).Decode(&po); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+
+		if err := validation.PurchaseOrder(&po); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Create(r.Context(), &po); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(po)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// =====================================================
+// /purchase-orders/{id}
+// GET  /purchase-orders/{id}
+// PUT  /purchase-orders/{id}          (draft saja)
+// POST /purchase-orders/{id}/order    (draft → ordered)
+// POST /purchase-orders/{id}/receive  (→ partially_received / received)
+// POST /purchase-orders/{id}/cancel
+// =====================================================
+func (h *PurchaseOrderHandler) PurchaseOrderByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/purchase-orders/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, apperror.ErrInvalidID.WithMessage("invalid purchase order id"))
+		return
+	}
+
+	switch action {
+	case "":
+	case "order":
+		h.order(w, r, id)
+		return
+	case "receive":
+		h.receive(w, r, id)
+		return
+	case "cancel":
+		h.cancel(w, r, id)
+		return
+	default:
+		writeError(w, apperror.ErrNotFound)
+		return
+	}
+
+	switch r.Method {
+
+	case http.MethodGet:
+		po, err := h.service.GetByID(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(po)
+
+	case http.MethodPut:
+		var po model.PurchaseOrder
+		if err := json.NewDecoder(r.Body).Decode(&po); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+		po.ID = id
+
+		if err := validation.PurchaseOrder(&po); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		updated, err := h.service.Update(r.Context(), &po)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(updated)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// POST /purchase-orders/{id}/order
+func (h *PurchaseOrderHandler) order(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	po, err := h.service.Order(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(po)
+}
+
+// =====================================================
+// POST /purchase-orders/{id}/receive
+// Body: { "items": [ { "item_id": 1, "quantity": 12 } ] }
+// items kosong / body kosong = terima semua sisa item
+// =====================================================
+func (h *PurchaseOrderHandler) receive(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var req model.ReceiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, apperror.ErrInvalidBody)
+		return
+	}
+
+	if err := validation.ReceiveRequest(&req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	po, err := h.service.Receive(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(po)
+}
+
+// POST /purchase-orders/{id}/cancel
+func (h *PurchaseOrderHandler) cancel(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	po, err := h.service.Cancel(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(po)
+}
+
+func parsePurchaseOrderFilter(r *http.Request) (model.PurchaseOrderFilter, error) {
+	q := r.URL.Query()
+	f := model.PurchaseOrderFilter{Status: q.Get("status")}
+
+	switch f.Status {
+	case "",
+		model.PurchaseOrderDraft,
+		model.PurchaseOrderOrdered,
+		model.PurchaseOrderPartiallyReceived,
+		model.PurchaseOrderReceived,
+		model.PurchaseOrderCancelled:
+	default:
+		return f, apperror.ErrInvalidQuery.WithMessage("invalid status")
+	}
+
+	if v := q.Get("supplier_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return f, apperror.ErrInvalidQuery.WithMessage("invalid supplier_id")
+		}
+		f.SupplierID = &id
+	}
+
+	return f, nil
+}
@@ -3,11 +3,19 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
 	"github.com/jackyansen22/crud-category/internal/service"
 )
 
+const (
+	defaultReportTopN = 5
+	maxReportTopN     = 50
+)
+
 type ReportHandler struct {
 	service service.ReportService
 }
@@ -18,16 +26,35 @@ func NewReportHandler(service service.ReportService) *ReportHandler {
 
 // ===============================
 // GET /report/hari-ini
+// GET /report/hari-ini?outlet_id=2&top_n=10&tz=Asia/Makassar
+// GET /report/hari-ini?format=csv|xlsx (atau header Accept)
 // ===============================
 func (h *ReportHandler) Today(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	format, err := parseExportFormat(r)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	data, err := h.service.GetToday(r.Context())
+	f, err := parseReportFilter(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+
+	data, err := h.service.GetToday(r.Context(), f)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if format != exportJSON {
+		writeReportExport(w, format, "report-hari-ini", data)
 		return
 	}
 
@@ -37,41 +64,238 @@ func (h *ReportHandler) Today(w http.ResponseWriter, r *http.Request) {
 
 // ===============================
 // GET /report?start_date=&end_date=
+// GET /report?start_date=&end_date=&outlet_id=2&top_n=10&tz=Asia/Makassar
+// GET /report?start_date=&end_date=&format=csv|xlsx (atau header Accept)
+// - tanggal = hari bisnis di timezone tz (default config)
 // ===============================
 func (h *ReportHandler) ByRange(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	format, err := parseExportFormat(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	f, err := parseReportFilter(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := parseDateRange(r, &f, true); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	data, err := h.service.GetByRange(r.Context(), f)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
+	if format != exportJSON {
+		name := "report-" + f.Start.Format("2006-01-02") + "_" + f.End.AddDate(0, 0, -1).Format("2006-01-02")
+		writeReportExport(w, format, name, data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+// ?outlet_id= & ?top_n= & ?tz=
+func parseReportFilter(r *http.Request) (model.ReportFilter, error) {
+	var (
+		f   model.ReportFilter
+		err error
+	)
+
+	if f.OutletID, err = parseOutletID(r); err != nil {
+		return f, err
+	}
+	if f.TopN, err = parseTopN(r); err != nil {
+		return f, err
+	}
+	if f.Location, err = parseTimezone(r); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// ?start_date=&end_date= (YYYY-MM-DD, end_date inclusive)
+// required=false: dua-duanya kosong = tanpa batas tanggal
+func parseDateRange(r *http.Request, f *model.ReportFilter, required bool) error {
 	startStr := r.URL.Query().Get("start_date")
 	endStr := r.URL.Query().Get("end_date")
 
+	if startStr == "" && endStr == "" && !required {
+		return nil
+	}
 	if startStr == "" || endStr == "" {
-		http.Error(w, "start_date and end_date are required", http.StatusBadRequest)
-		return
+		return apperror.ErrInvalidQuery.WithMessage("start_date and end_date are required")
 	}
 
 	start, err := time.Parse("2006-01-02", startStr)
 	if err != nil {
-		http.Error(w, "invalid start_date format", http.StatusBadRequest)
-		return
+		return apperror.ErrInvalidQuery.WithMessage("invalid start_date format")
 	}
 	end, err := time.Parse("2006-01-02", endStr)
 	if err != nil {
-		http.Error(w, "invalid end_date format", http.StatusBadRequest)
-		return
+		return apperror.ErrInvalidQuery.WithMessage("invalid end_date format")
+	}
+
+	if end.Before(start) {
+		return apperror.ErrInvalidQuery.WithMessage("end_date must not be before start_date")
 	}
 
 	// end date exclusive
-	end = end.Add(24 * time.Hour)
+	f.Start = start
+	f.End = end.AddDate(0, 0, 1)
+	return nil
+}
 
-	data, err := h.service.GetByRange(r.Context(), start, end)
+// =====================================================
+// Report → CSV / XLSX, satu section per sheet
+// =====================================================
+func writeReportExport(w http.ResponseWriter, format, filename string, data *model.ReportResponse) {
+	ew := newExportWriter(w, format, filename)
+
+	err := writeReportSheets(ew, data)
+	if err == nil {
+		err = ew.Close()
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		writeExportError(w, ew, err)
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+func writeReportSheets(ew exportWriter, data *model.ReportResponse) error {
+	if err := ew.Sheet("Ringkasan", "metric", "value"); err != nil {
+		return err
+	}
+	summary := [][]any{
+		{"total_revenue", data.TotalRevenue},
+		{"total_refund", data.TotalRefund},
+		{"cogs", data.COGS},
+		{"gross_profit", data.GrossProfit},
+		{"margin", data.Margin},
+		{"total_transaksi", data.TotalTransaksi},
+		{"rata_rata_basket", data.RataRataBasket},
+		{"item_per_transaksi", data.ItemPerTrx},
+	}
+	for _, row := range summary {
+		if err := ew.Row(row...); err != nil {
+			return err
+		}
+	}
+
+	if err := ew.Sheet("Harian", "tanggal", "revenue", "total_refund", "total_transaksi", "qty_terjual"); err != nil {
+		return err
+	}
+	for _, d := range data.Harian {
+		if err := ew.Row(d.Tanggal, d.Revenue, d.TotalRefund, d.TotalTransaksi, d.QtyTerjual); err != nil {
+			return err
+		}
+	}
+
+	if err := ew.Sheet("Kategori", "category_id", "nama", "qty_terjual", "revenue", "cogs", "gross_profit", "margin"); err != nil {
+		return err
+	}
+	for _, c := range data.Kategori {
+		if err := ew.Row(c.CategoryID, c.Nama, c.QtyTerjual, c.Revenue, c.COGS, c.GrossProfit, c.Margin); err != nil {
+			return err
+		}
+	}
+
+	// baris varian tepat di bawah produk induknya
+	if err := ew.Sheet("Produk", "product_id", "variant_id", "nama", "variant", "qty_terjual", "revenue", "cogs", "gross_profit", "margin"); err != nil {
+		return err
+	}
+	for _, p := range data.Produk {
+		if err := ew.Row(p.ProductID, nil, p.Nama, "", p.QtyTerjual, p.Revenue, p.COGS, p.GrossProfit, p.Margin); err != nil {
+			return err
+		}
+		for _, v := range p.Variants {
+			err := ew.Row(p.ProductID, v.VariantID, p.Nama, formatAttributes(v.Attributes),
+				v.QtyTerjual, v.Revenue, v.COGS, v.GrossProfit, v.Margin)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	ranks := []struct {
+		sheet string
+		items []model.ProductRank
+	}{
+		{"Top Qty", data.TopByQty},
+		{"Top Revenue", data.TopByRevenue},
+	}
+	for _, rank := range ranks {
+		if err := ew.Sheet(rank.sheet, "rank", "product_id", "nama", "qty_terjual", "revenue"); err != nil {
+			return err
+		}
+		for i, p := range rank.items {
+			if err := ew.Row(i+1, p.ProductID, p.Nama, p.QtyTerjual, p.Revenue); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := ew.Sheet("Pembayaran", "method", "total", "jumlah_transaksi"); err != nil {
+		return err
+	}
+	for _, p := range data.Payments {
+		if err := ew.Row(p.Method, p.Total, p.JumlahTransaksi); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ?outlet_id= (kosong = semua outlet)
+func parseOutletID(r *http.Request) (*int, error) {
+	v := r.URL.Query().Get("outlet_id")
+	if v == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.Atoi(v)
+	if err != nil || id < 1 {
+		return nil, apperror.ErrInvalidQuery.WithMessage("invalid outlet_id")
+	}
+	return &id, nil
+}
+
+// ?tz=Asia/Jakarta (IANA, kosong = timezone bisnis dari config)
+func parseTimezone(r *http.Request) (*time.Location, error) {
+	v := r.URL.Query().Get("tz")
+	if v == "" {
+		return nil, nil
+	}
+
+	// "Local" = timezone server, justru yang mau dihindari
+	loc, err := time.LoadLocation(v)
+	if err != nil || v == "Local" {
+		return nil, apperror.ErrInvalidQuery.WithMessage("invalid tz")
+	}
+	return loc, nil
+}
+
+// ?top_n= (default 5)
+func parseTopN(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("top_n")
+	if v == "" {
+		return defaultReportTopN, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxReportTopN {
+		return 0, apperror.ErrInvalidQuery.WithMessage("invalid top_n (1-" + strconv.Itoa(maxReportTopN) + ")")
+	}
+	return n, nil
 }
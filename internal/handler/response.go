@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+)
+
+// =====================================================
+// ERROR RESPONSE
+// { "code": "INSUFFICIENT_STOCK", "message": "...", "details": {...} }
+// error yang bukan apperror (SQL, dll) → 500 INTERNAL_ERROR,
+// detail aslinya hanya di log
+// =====================================================
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details"`
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Kind == apperror.KindInternal {
+		log.Println("❌ INTERNAL ERROR:", err)
+		appErr = apperror.ErrInternal
+		err = appErr
+	}
+
+	writeJSON(w, appErr.Kind.HTTPStatus(), errorResponse{
+		Code:    appErr.Code,
+		Message: err.Error(),
+		Details: appErr.Details,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/service"
+)
+
+type StockHandler struct {
+	service service.StockService
+}
+
+func NewStockHandler(service service.StockService) *StockHandler {
+	return &StockHandler{service: service}
+}
+
+// =====================================================
+// /stock/consistency
+// GET  → daftar produk yang stok-nya beda dengan ledger
+// POST → rebuild stok dari ledger, return yang diperbaiki
+// =====================================================
+func (h *StockHandler) Consistency(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		drifts []model.StockDrift
+		err    error
+	)
+
+	switch r.Method {
+	case http.MethodGet:
+		drifts, err = h.service.CheckConsistency(r.Context())
+	case http.MethodPost:
+		drifts, err = h.service.Rebuild(r.Context())
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"consistent": len(drifts) == 0,
+		"drifts":     drifts,
+	})
+}
+
+// =====================================================
+// GET /product/{id}/stock-history
+// =====================================================
+func (h *StockHandler) History(w http.ResponseWriter, r *http.Request, productID int) {
+	if r.Method != http.MethodGet {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	movements, err := h.service.GetHistory(r.Context(), productID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(movements)
+}
+
+// =====================================================
+// POST /product/{id}/stock
+// Body: { "type": "receiving", "quantity": 24, "reason": "kiriman supplier" }
+// =====================================================
+func (h *StockHandler) Adjust(w http.ResponseWriter, r *http.Request, productID int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var req model.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperror.ErrInvalidBody)
+		return
+	}
+
+	movement, err := h.service.Adjust(r.Context(), productID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
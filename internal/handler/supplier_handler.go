@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/service"
+	"github.com/jackyansen22/crud-category/internal/validation"
+)
+
+type SupplierHandler struct {
+	service service.SupplierService
+}
+
+func NewSupplierHandler(service service.SupplierService) *SupplierHandler {
+	return &SupplierHandler{service: service}
+}
+
+// =====================================================
+// /suppliers
+// GET  /suppliers
+// POST /suppliers  Body: { "name": "PT Sumber Makmur", "phone": "...", "email": "...", "address": "..." }
+// =====================================================
+func (h *SupplierHandler) Suppliers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+
+	case http.MethodGet:
+		suppliers, err := h.service.GetAll(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(suppliers)
+
+	case http.MethodPost:
+		var s model.Supplier
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+
+		if err := validation.Supplier(&s); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Create(r.Context(), &s); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(s)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// =====================================================
+// /suppliers/{id}
+// GET / PUT
+// =====================================================
+func (h *SupplierHandler) SupplierByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/suppliers/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, apperror.ErrInvalidID.WithMessage("invalid supplier id"))
+		return
+	}
+	if action != "" {
+		writeError(w, apperror.ErrNotFound)
+		return
+	}
+
+	switch r.Method {
+
+	case http.MethodGet:
+		s, err := h.service.GetByID(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(s)
+
+	case http.MethodPut:
+		var s model.Supplier
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+		s.ID = id
+
+		if err := validation.Supplier(&s); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Update(r.Context(), &s); err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(s)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
@@ -2,14 +2,21 @@ package handler
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/jackyansen22/crud-category/internal/apperror"
 	"github.com/jackyansen22/crud-category/internal/model"
 	"github.com/jackyansen22/crud-category/internal/service"
+	"github.com/jackyansen22/crud-category/internal/validation"
 )
 
+const maxIdempotencyKeyLength = 255
+
+var errIdempotencyKeyTooLong = apperror.Validation("INVALID_IDEMPOTENCY_KEY", "Idempotency-Key too long")
+
 type TransactionHandler struct {
 	service service.TransactionService
 }
@@ -20,45 +27,80 @@ func NewTransactionHandler(service service.TransactionService) *TransactionHandl
 
 // =====================================================
 // POST /checkout
-// Body: { "items": [ { "product_id": 1, "quantity": 2 } ] }
+// Body: { "outlet_id": 2, "items": [ { "product_id": 1, "quantity": 2 },
+// { "barcode": "4006381333931", "quantity": 1 }, { "sku": "IDM-GRG", "quantity": 1 } ],
+// "payments": [ { "method": "cash", "amount": 50000 } ] }
+// outlet_id kosong = outlet default
+// Header (optional): Idempotency-Key: <uuid dari tablet>
 // =====================================================
 func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, apperror.ErrMethodNotAllowed)
 		return
 	}
 
 	var req model.CheckoutRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		writeError(w, apperror.ErrInvalidBody)
 		return
 	}
 
-	if len(req.Items) == 0 {
-		http.Error(w, "checkout items cannot be empty", http.StatusBadRequest)
+	if err := validation.CheckoutRequest(&req); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	transaction, err := h.service.Checkout(r.Context(), req.Items)
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			writeError(w, errIdempotencyKeyTooLong)
+			return
+		}
+		req.Idempotency = &model.IdempotencyKey{Key: key}
+	}
+
+	transaction, err := h.service.Checkout(r.Context(), &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if transaction.Replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(transaction)
 }
 
+// =====================================================
+// GET /transactions?limit=50&sort=created_at&order=desc&cursor=
+// GET /transactions?format=csv|xlsx (atau header Accept) → export
+// =====================================================
 func (h *TransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, apperror.ErrMethodNotAllowed)
 		return
 	}
 
-	data, err := h.service.GetAll(r.Context())
+	format, err := parseExportFormat(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+	if format != exportJSON {
+		h.export(w, r, format)
+		return
+	}
+
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	data, err := h.service.GetAll(r.Context(), page)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -66,25 +108,146 @@ func (h *TransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+// =====================================================
+// GET /transactions?format=csv&start_date=&end_date=&outlet_id=&tz=
+// - satu baris per detail line (untuk accounting), tanpa pagination
+// - tanggal optional (kosong = semua transaksi), created_at di timezone bisnis
+// =====================================================
+func (h *TransactionHandler) export(w http.ResponseWriter, r *http.Request, format string) {
+	f, err := parseReportFilter(r)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
+	if err := parseDateRange(r, &f, false); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ew := newExportWriter(w, format, "transactions")
+
+	err = ew.Sheet("Transaksi",
+		"transaction_id", "created_at", "status", "outlet_id", "outlet", "kasir",
+		"total_amount", "payments",
+		"detail_id", "product_id", "product_name", "variant_id", "variant",
+		"category_id", "category", "unit_price", "unit_cost", "quantity", "subtotal",
+		"refunded_qty", "refunded_amount",
+	)
+	if err == nil {
+		err = h.service.Export(r.Context(), f, func(l model.TransactionExportLine) error {
+			d := l.Detail
+			return ew.Row(
+				l.TransactionID, l.CreatedAt, l.Status, l.OutletID, l.OutletName, l.Cashier,
+				l.TotalAmount, l.Payments,
+				d.ID, d.ProductID, d.ProductName, d.VariantID, formatAttributes(d.VariantAttributes),
+				d.CategoryID, d.CategoryName, d.UnitPrice, d.UnitCost, d.Quantity, d.Subtotal,
+				l.RefundedQty, l.RefundedTotal,
+			)
+		})
+	}
+	if err == nil {
+		err = ew.Close()
+	}
+	if err != nil {
+		writeExportError(w, ew, err)
+	}
+}
 
-	idStr := strings.TrimPrefix(r.URL.Path, "/transactions/")
+// =====================================================
+// /transactions/{id}
+// GET  /transactions/{id}
+// POST /transactions/{id}/refund
+// POST /transactions/{id}/void
+// =====================================================
+func (h *TransactionHandler) TransactionByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/transactions/"), "/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "invalid transaction id", http.StatusBadRequest)
+		writeError(w, apperror.ErrInvalidID.WithMessage("invalid transaction id"))
+		return
+	}
+
+	switch action {
+	case "":
+		h.getByID(w, r, id)
+	case "refund":
+		h.refund(w, r, id)
+	case "void":
+		h.void(w, r, id)
+	default:
+		writeError(w, apperror.ErrNotFound)
+	}
+}
+
+func (h *TransactionHandler) getByID(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodGet {
+		writeError(w, apperror.ErrMethodNotAllowed)
 		return
 	}
 
 	data, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
+
+// =====================================================
+// POST /transactions/{id}/refund
+// Body: { "reason": "rusak", "items": [ { "transaction_detail_id": 1, "quantity": 1 } ] }
+// items kosong / body kosong = refund semua sisa item
+// =====================================================
+func (h *TransactionHandler) refund(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var req model.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, apperror.ErrInvalidBody)
+		return
+	}
+
+	refund, err := h.service.Refund(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(refund)
+}
+
+// =====================================================
+// POST /transactions/{id}/void
+// Body (optional): { "reason": "salah input" }
+// =====================================================
+func (h *TransactionHandler) void(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, apperror.ErrInvalidBody)
+		return
+	}
+
+	refund, err := h.service.Void(r.Context(), id, req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(refund)
+}
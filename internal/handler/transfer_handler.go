@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/service"
+	"github.com/jackyansen22/crud-category/internal/validation"
+)
+
+type TransferHandler struct {
+	service service.TransferService
+}
+
+func NewTransferHandler(service service.TransferService) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+// =====================================================
+// /transfers
+// GET  /transfers?status=in_transit&limit=20&cursor=
+// POST /transfers
+// Body: { "from_outlet_id": 1, "to_outlet_id": 2, "note": "restock",
+// "items": [ { "product_id": 1, "quantity": 10 }, { "product_id": 2, "variant_id": 5, "quantity": 3 } ] }
+// =====================================================
+func (h *TransferHandler) Transfers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+
+	case http.MethodGet:
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "", model.TransferStatusInTransit, model.TransferStatusReceived, model.TransferStatusCancelled:
+		default:
+			writeError(w, apperror.ErrInvalidQuery.WithMessage("invalid status (in_transit/received/cancelled)"))
+			return
+		}
+
+		page, err := parsePageRequest(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		transfers, err := h.service.GetAll(r.Context(), status, page)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(transfers)
+
+	case http.MethodPost:
+		var t model.StockTransfer
+		if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+
+		if err := validation.StockTransfer(&t); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Create(r.Context(), &t); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(t)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// =====================================================
+// /transfers/{id}
+// GET  /transfers/{id}
+// POST /transfers/{id}/receive
+// POST /transfers/{id}/cancel
+// =====================================================
+func (h *TransferHandler) TransferByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/transfers/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, apperror.ErrInvalidID.WithMessage("invalid transfer id"))
+		return
+	}
+
+	switch action {
+	case "":
+		h.getByID(w, r, id)
+	case "receive":
+		h.receive(w, r, id)
+	case "cancel":
+		h.cancel(w, r, id)
+	default:
+		writeError(w, apperror.ErrNotFound)
+	}
+}
+
+func (h *TransferHandler) getByID(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodGet {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	t, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(t)
+}
+
+// POST /transfers/{id}/receive (stok masuk outlet tujuan)
+func (h *TransferHandler) receive(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	t, err := h.service.Receive(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(t)
+}
+
+// POST /transfers/{id}/cancel (stok kembali ke outlet asal)
+func (h *TransferHandler) cancel(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeError(w, apperror.ErrMethodNotAllowed)
+		return
+	}
+
+	t, err := h.service.Cancel(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(t)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/service"
+	"github.com/jackyansen22/crud-category/internal/validation"
+)
+
+type VariantHandler struct {
+	service service.VariantService
+}
+
+func NewVariantHandler(service service.VariantService) *VariantHandler {
+	return &VariantHandler{service: service}
+}
+
+// =====================================================
+// /product/{id}/variants
+// GET  → daftar varian aktif
+// POST → tambah varian
+// Body: { "attributes": {"size": "L", "color": "red"}, "harga": 75000, "stok": 10, "sku": "TS-RED-L" }
+// =====================================================
+func (h *VariantHandler) Variants(w http.ResponseWriter, r *http.Request, productID int) {
+	switch r.Method {
+
+	case http.MethodGet:
+		variants, err := h.service.GetByProduct(r.Context(), productID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(variants)
+
+	case http.MethodPost:
+		var v model.ProductVariant
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+		v.ProductID = productID
+
+		if err := validation.Variant(&v); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Create(r.Context(), &v); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(v)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
+
+// =====================================================
+// /product/{id}/variants/{vid}
+// GET / PUT / DELETE
+// =====================================================
+func (h *VariantHandler) VariantByID(w http.ResponseWriter, r *http.Request, productID int, vidStr string) {
+	variantID, err := strconv.Atoi(vidStr)
+	if err != nil {
+		writeError(w, apperror.ErrInvalidID.WithMessage("invalid variant id"))
+		return
+	}
+
+	switch r.Method {
+
+	case http.MethodGet:
+		v, err := h.service.GetByID(r.Context(), productID, variantID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(v)
+
+	case http.MethodPut:
+		var v model.ProductVariant
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			writeError(w, apperror.ErrInvalidBody)
+			return
+		}
+		v.ID = variantID
+		v.ProductID = productID
+
+		if err := validation.Variant(&v); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := h.service.Update(r.Context(), &v); err != nil {
+			writeError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(v)
+
+	case http.MethodDelete:
+		if err := h.service.Delete(r.Context(), productID, variantID); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, apperror.ErrMethodNotAllowed)
+	}
+}
@@ -0,0 +1,4 @@
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES categories (id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
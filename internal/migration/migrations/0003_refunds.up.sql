@@ -0,0 +1,24 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'completed';
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions (id),
+    type           VARCHAR(16) NOT NULL CHECK (type IN ('refund', 'void')),
+    reason         TEXT NOT NULL DEFAULT '',
+    total_amount   INTEGER NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS refund_items (
+    id                    SERIAL PRIMARY KEY,
+    refund_id             INTEGER NOT NULL REFERENCES refunds (id) ON DELETE CASCADE,
+    transaction_detail_id INTEGER NOT NULL REFERENCES transaction_details (id),
+    product_id            INTEGER NOT NULL REFERENCES products (id),
+    quantity              INTEGER NOT NULL CHECK (quantity > 0),
+    amount                INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_transaction_id ON refunds (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_created_at ON refunds (created_at);
+CREATE INDEX IF NOT EXISTS idx_refund_items_transaction_detail_id ON refund_items (transaction_detail_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS transaction_payments;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS change_amount,
+    DROP COLUMN IF EXISTS paid_amount;
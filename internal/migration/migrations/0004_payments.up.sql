@@ -0,0 +1,13 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS paid_amount INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS change_amount INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS transaction_payments (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    method         VARCHAR(16) NOT NULL CHECK (method IN ('cash', 'card', 'qris', 'ewallet')),
+    amount         INTEGER NOT NULL,
+    tendered       INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_transaction_id ON transaction_payments (transaction_id);
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id           BIGSERIAL PRIMARY KEY,
+    product_id   INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    type         VARCHAR(16) NOT NULL
+                 CHECK (type IN ('sale', 'refund', 'adjustment', 'receiving', 'stocktake')),
+    quantity     INTEGER NOT NULL,
+    stock_after  INTEGER NOT NULL,
+    reason       TEXT NOT NULL DEFAULT '',
+    reference_id INTEGER,
+    user_id      INTEGER,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements (product_id, id);
+
+-- ledger append-only: tidak boleh UPDATE / DELETE
+-- (kecuali cascade dari DELETE products, trigger depth > 1)
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND pg_trigger_depth() > 1 THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_stock_movements_append_only
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- saldo awal: stok yang sudah ada sebelum ledger
+INSERT INTO stock_movements (product_id, type, quantity, stock_after, reason)
+SELECT id, 'stocktake', stok, stok, 'opening balance'
+FROM products;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_transactions_created_at_id;
+DROP INDEX IF EXISTS idx_products_created_at_id;
+DROP INDEX IF EXISTS idx_products_harga_id;
+DROP INDEX IF EXISTS idx_products_nama_id;
+
+DROP TRIGGER IF EXISTS trg_products_updated_at ON products;
+DROP FUNCTION IF EXISTS set_updated_at();
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,21 @@
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- updated_at otomatis untuk semua UPDATE products (termasuk perubahan stok)
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_updated_at
+    BEFORE UPDATE ON products
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- index untuk sort / keyset pagination
+CREATE INDEX IF NOT EXISTS idx_products_nama_id ON products (nama, id);
+CREATE INDEX IF NOT EXISTS idx_products_harga_id ON products (harga, id);
+CREATE INDEX IF NOT EXISTS idx_products_created_at_id ON products (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at_id ON transactions (created_at, id);
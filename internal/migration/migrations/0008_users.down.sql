@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_transactions_user_id;
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS fk_stock_movements_user_id;
+ALTER TABLE refunds DROP COLUMN IF EXISTS user_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS user_id;
+
+DROP TABLE IF EXISTS users;
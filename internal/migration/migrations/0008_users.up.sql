@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS users (
+    id            SERIAL PRIMARY KEY,
+    username      VARCHAR(64) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    role          VARCHAR(16) NOT NULL CHECK (role IN ('admin', 'manager', 'cashier')),
+    active        BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users (id);
+
+ALTER TABLE refunds
+    ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users (id);
+
+ALTER TABLE stock_movements
+    ADD CONSTRAINT fk_stock_movements_user_id FOREIGN KEY (user_id) REFERENCES users (id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions (user_id);
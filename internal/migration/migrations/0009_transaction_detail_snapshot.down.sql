@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_transaction_details_product_id;
+
+ALTER TABLE transaction_details
+    DROP CONSTRAINT IF EXISTS transaction_details_product_id_fkey,
+    ADD CONSTRAINT transaction_details_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products (id);
+
+ALTER TABLE transaction_details
+    DROP COLUMN IF EXISTS category_name,
+    DROP COLUMN IF EXISTS category_id,
+    DROP COLUMN IF EXISTS product_name,
+    DROP COLUMN IF EXISTS unit_price;
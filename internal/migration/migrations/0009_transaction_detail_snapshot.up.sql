@@ -0,0 +1,36 @@
+-- snapshot produk saat checkout: rename / delete produk tidak mengubah histori
+ALTER TABLE transaction_details
+    ADD COLUMN IF NOT EXISTS unit_price    INTEGER,
+    ADD COLUMN IF NOT EXISTS product_name  VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS category_id   INTEGER,
+    ADD COLUMN IF NOT EXISTS category_name VARCHAR(255);
+
+-- backfill transaksi lama dari data produk sekarang (best effort)
+UPDATE transaction_details td
+SET unit_price    = td.subtotal / NULLIF(td.quantity, 0),
+    product_name  = p.nama,
+    category_id   = p.category_id,
+    category_name = c.name
+FROM products p
+LEFT JOIN categories c ON c.id = p.category_id
+WHERE p.id = td.product_id
+  AND td.product_name IS NULL;
+
+UPDATE transaction_details
+SET unit_price = COALESCE(unit_price, 0),
+    product_name = COALESCE(product_name, ''),
+    category_name = COALESCE(category_name, '');
+
+ALTER TABLE transaction_details
+    ALTER COLUMN unit_price SET NOT NULL,
+    ALTER COLUMN product_name SET NOT NULL,
+    ALTER COLUMN category_name SET NOT NULL,
+    ALTER COLUMN category_name SET DEFAULT '';
+
+-- produk yang pernah terjual tidak boleh di-hard delete
+ALTER TABLE transaction_details
+    DROP CONSTRAINT IF EXISTS transaction_details_product_id_fkey,
+    ADD CONSTRAINT transaction_details_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_transaction_details_product_id ON transaction_details (product_id);
@@ -0,0 +1,10 @@
+-- soft delete: NULL = aktif, diisi = dihapus (di-purge setelah retention)
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- index untuk purge job
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS product_barcodes;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_sku_key;
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
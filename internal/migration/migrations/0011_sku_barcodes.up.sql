@@ -0,0 +1,18 @@
+-- SKU unik per produk (NULL untuk produk lama yang belum punya SKU)
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+
+ALTER TABLE products
+    ADD CONSTRAINT products_sku_key UNIQUE (sku);
+
+-- satu produk bisa punya beberapa barcode (EAN-13 / UPC-A)
+-- code disimpan sebagai GTIN-13 (UPC-A diberi prefix 0)
+CREATE TABLE IF NOT EXISTS product_barcodes (
+    id         SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    code       VARCHAR(13) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT product_barcodes_code_key UNIQUE (code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_barcodes_product_id ON product_barcodes (product_id);
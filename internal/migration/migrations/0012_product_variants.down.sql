@@ -0,0 +1,15 @@
+ALTER TABLE transaction_details
+    DROP COLUMN IF EXISTS variant_attributes,
+    DROP COLUMN IF EXISTS variant_id;
+
+DROP INDEX IF EXISTS idx_stock_movements_variant_id;
+
+-- movement varian tidak punya arti tanpa tabel varian
+ALTER TABLE stock_movements DISABLE TRIGGER trg_stock_movements_append_only;
+DELETE FROM stock_movements WHERE variant_id IS NOT NULL;
+ALTER TABLE stock_movements ENABLE TRIGGER trg_stock_movements_append_only;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS variant_id;
+
+DROP TRIGGER IF EXISTS trg_products_sku_unique ON products;
+DROP TABLE IF EXISTS product_variants;
+DROP FUNCTION IF EXISTS check_sku_unique();
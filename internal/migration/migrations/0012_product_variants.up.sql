@@ -0,0 +1,63 @@
+-- varian produk (ukuran / rasa) dengan harga, stok, SKU sendiri
+CREATE TABLE IF NOT EXISTS product_variants (
+    id         SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku        VARCHAR(64),
+    attributes JSONB NOT NULL DEFAULT '{}',
+    harga      INTEGER NOT NULL DEFAULT 0,
+    stok       INTEGER NOT NULL DEFAULT 0,
+    active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT product_variants_sku_key UNIQUE (sku)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants (product_id, id);
+
+CREATE TRIGGER trg_product_variants_updated_at
+    BEFORE UPDATE ON product_variants
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- SKU unik lintas products & product_variants
+-- (error memakai nama constraint unik tabel asal supaya mapping error sama)
+CREATE OR REPLACE FUNCTION check_sku_unique() RETURNS trigger AS $$
+BEGIN
+    IF NEW.sku IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF TG_TABLE_NAME = 'products'
+       AND EXISTS (SELECT 1 FROM product_variants WHERE sku = NEW.sku) THEN
+        RAISE EXCEPTION 'sku % already used by a variant', NEW.sku
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'products_sku_key';
+    END IF;
+
+    IF TG_TABLE_NAME = 'product_variants'
+       AND EXISTS (SELECT 1 FROM products WHERE sku = NEW.sku) THEN
+        RAISE EXCEPTION 'sku % already used by a product', NEW.sku
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'product_variants_sku_key';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_sku_unique
+    BEFORE INSERT OR UPDATE OF sku ON products
+    FOR EACH ROW EXECUTE FUNCTION check_sku_unique();
+
+CREATE TRIGGER trg_product_variants_sku_unique
+    BEFORE INSERT OR UPDATE OF sku ON product_variants
+    FOR EACH ROW EXECUTE FUNCTION check_sku_unique();
+
+-- ledger per varian (NULL = stok produk tanpa varian)
+ALTER TABLE stock_movements
+    ADD COLUMN IF NOT EXISTS variant_id INTEGER REFERENCES product_variants (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_variant_id ON stock_movements (variant_id, id);
+
+-- snapshot varian saat checkout
+ALTER TABLE transaction_details
+    ADD COLUMN IF NOT EXISTS variant_id INTEGER REFERENCES product_variants (id) ON DELETE RESTRICT,
+    ADD COLUMN IF NOT EXISTS variant_attributes JSONB;
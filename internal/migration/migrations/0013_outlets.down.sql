@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS stock_transfer_items;
+DROP TABLE IF EXISTS stock_transfers;
+
+DROP INDEX IF EXISTS idx_transactions_outlet_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS outlet_id;
+
+-- movement transfer tidak punya arti tanpa outlet
+ALTER TABLE stock_movements DISABLE TRIGGER trg_stock_movements_append_only;
+DELETE FROM stock_movements WHERE type IN ('transfer_out', 'transfer_in');
+ALTER TABLE stock_movements ENABLE TRIGGER trg_stock_movements_append_only;
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_type_check
+    CHECK (type IN ('sale', 'refund', 'adjustment', 'receiving', 'stocktake'));
+
+DROP INDEX IF EXISTS idx_stock_movements_outlet_id;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS outlet_id;
+
+DROP TABLE IF EXISTS outlet_stock;
+DROP TABLE IF EXISTS outlets;
@@ -0,0 +1,103 @@
+-- outlet / gudang; satu outlet default untuk data lama & checkout tanpa outlet_id
+CREATE TABLE IF NOT EXISTS outlets (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    address    TEXT NOT NULL DEFAULT '',
+    active     BOOLEAN NOT NULL DEFAULT TRUE,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT outlets_name_key UNIQUE (name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS outlets_default_key ON outlets (is_default) WHERE is_default;
+
+CREATE TRIGGER trg_outlets_updated_at
+    BEFORE UPDATE ON outlets
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+INSERT INTO outlets (name, is_default) VALUES ('Pusat', TRUE);
+
+-- stok per outlet (products.stok / product_variants.stok = total semua outlet)
+CREATE TABLE IF NOT EXISTS outlet_stock (
+    id         SERIAL PRIMARY KEY,
+    outlet_id  INTEGER NOT NULL REFERENCES outlets (id) ON DELETE RESTRICT,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants (id) ON DELETE CASCADE,
+    stok       INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS outlet_stock_item_key
+    ON outlet_stock (outlet_id, product_id, COALESCE(variant_id, 0));
+
+CREATE TRIGGER trg_outlet_stock_updated_at
+    BEFORE UPDATE ON outlet_stock
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- stok yang sudah ada masuk ke outlet default
+INSERT INTO outlet_stock (outlet_id, product_id, variant_id, stok)
+SELECT o.id, p.id, NULL, p.stok
+FROM products p
+CROSS JOIN outlets o
+WHERE o.is_default;
+
+INSERT INTO outlet_stock (outlet_id, product_id, variant_id, stok)
+SELECT o.id, v.product_id, v.id, v.stok
+FROM product_variants v
+CROSS JOIN outlets o
+WHERE o.is_default;
+
+-- ledger per outlet
+ALTER TABLE stock_movements
+    ADD COLUMN IF NOT EXISTS outlet_id INTEGER REFERENCES outlets (id) ON DELETE RESTRICT;
+
+ALTER TABLE stock_movements DISABLE TRIGGER trg_stock_movements_append_only;
+UPDATE stock_movements SET outlet_id = (SELECT id FROM outlets WHERE is_default);
+ALTER TABLE stock_movements ENABLE TRIGGER trg_stock_movements_append_only;
+
+ALTER TABLE stock_movements ALTER COLUMN outlet_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_outlet_id ON stock_movements (outlet_id, id);
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_type_check
+    CHECK (type IN ('sale', 'refund', 'adjustment', 'receiving', 'stocktake', 'transfer_out', 'transfer_in'));
+
+-- checkout per outlet
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS outlet_id INTEGER REFERENCES outlets (id) ON DELETE RESTRICT;
+
+UPDATE transactions SET outlet_id = (SELECT id FROM outlets WHERE is_default);
+
+ALTER TABLE transactions ALTER COLUMN outlet_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_outlet_id ON transactions (outlet_id, created_at);
+
+-- transfer stok antar outlet
+-- in_transit: stok sudah keluar dari outlet asal, belum masuk outlet tujuan
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id             SERIAL PRIMARY KEY,
+    from_outlet_id INTEGER NOT NULL REFERENCES outlets (id) ON DELETE RESTRICT,
+    to_outlet_id   INTEGER NOT NULL REFERENCES outlets (id) ON DELETE RESTRICT,
+    status         VARCHAR(16) NOT NULL DEFAULT 'in_transit'
+                   CHECK (status IN ('in_transit', 'received', 'cancelled')),
+    note           TEXT NOT NULL DEFAULT '',
+    user_id        INTEGER REFERENCES users (id),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    received_at    TIMESTAMPTZ,
+    cancelled_at   TIMESTAMPTZ,
+    CHECK (from_outlet_id <> to_outlet_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON stock_transfers (status, id);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id          SERIAL PRIMARY KEY,
+    transfer_id INTEGER NOT NULL REFERENCES stock_transfers (id) ON DELETE CASCADE,
+    product_id  INTEGER NOT NULL REFERENCES products (id) ON DELETE RESTRICT,
+    variant_id  INTEGER REFERENCES product_variants (id) ON DELETE RESTRICT,
+    quantity    INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_items_transfer_id ON stock_transfer_items (transfer_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS product_cost_history;
+
+ALTER TABLE products DROP COLUMN IF EXISTS harga_pokok;
+
+DROP TABLE IF EXISTS purchase_order_items;
+DROP TABLE IF EXISTS purchase_orders;
+DROP TABLE IF EXISTS suppliers;
@@ -0,0 +1,70 @@
+CREATE TABLE IF NOT EXISTS suppliers (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    phone      VARCHAR(32) NOT NULL DEFAULT '',
+    email      VARCHAR(255) NOT NULL DEFAULT '',
+    address    TEXT NOT NULL DEFAULT '',
+    active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT suppliers_name_key UNIQUE (name)
+);
+
+CREATE TRIGGER trg_suppliers_updated_at
+    BEFORE UPDATE ON suppliers
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- draft → ordered → partially_received → received
+-- (draft / ordered boleh cancelled)
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id          SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers (id) ON DELETE RESTRICT,
+    outlet_id   INTEGER NOT NULL REFERENCES outlets (id) ON DELETE RESTRICT,
+    status      VARCHAR(24) NOT NULL DEFAULT 'draft'
+                CHECK (status IN ('draft', 'ordered', 'partially_received', 'received', 'cancelled')),
+    note        TEXT NOT NULL DEFAULT '',
+    user_id     INTEGER REFERENCES users (id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ordered_at  TIMESTAMPTZ,
+    received_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_status ON purchase_orders (status, id);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier_id ON purchase_orders (supplier_id, id);
+
+CREATE TRIGGER trg_purchase_orders_updated_at
+    BEFORE UPDATE ON purchase_orders
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS purchase_order_items (
+    id                SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    product_id        INTEGER NOT NULL REFERENCES products (id) ON DELETE RESTRICT,
+    variant_id        INTEGER REFERENCES product_variants (id) ON DELETE RESTRICT,
+    quantity          INTEGER NOT NULL CHECK (quantity > 0),
+    unit_cost         INTEGER NOT NULL CHECK (unit_cost >= 0),
+    received_quantity INTEGER NOT NULL DEFAULT 0,
+    CHECK (received_quantity BETWEEN 0 AND quantity)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_order_items_po_id ON purchase_order_items (purchase_order_id);
+
+-- harga pokok (HPP) = weighted-average cost dari penerimaan barang
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS harga_pokok INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS product_cost_history (
+    id                BIGSERIAL PRIMARY KEY,
+    product_id        INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    variant_id        INTEGER REFERENCES product_variants (id) ON DELETE CASCADE,
+    purchase_order_id INTEGER REFERENCES purchase_orders (id) ON DELETE SET NULL,
+    quantity          INTEGER NOT NULL,
+    unit_cost         INTEGER NOT NULL,
+    stock_before      INTEGER NOT NULL,
+    cost_before       INTEGER NOT NULL,
+    cost_after        INTEGER NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_cost_history_product_id ON product_cost_history (product_id, id);
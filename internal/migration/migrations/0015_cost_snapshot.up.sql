@@ -0,0 +1,9 @@
+-- HPP per unit saat checkout (untuk COGS / gross profit)
+ALTER TABLE transaction_details
+    ADD COLUMN IF NOT EXISTS unit_cost INTEGER NOT NULL DEFAULT 0;
+
+-- transaksi lama: pakai HPP produk sekarang (perkiraan terbaik)
+UPDATE transaction_details td
+SET unit_cost = p.harga_pokok
+FROM products p
+WHERE p.id = td.product_id;
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS trg_categories_version ON categories;
+DROP TRIGGER IF EXISTS trg_products_version ON products;
+DROP FUNCTION IF EXISTS bump_version();
+
+ALTER TABLE categories DROP COLUMN IF EXISTS version;
+ALTER TABLE products DROP COLUMN IF EXISTS version;
@@ -0,0 +1,22 @@
+-- optimistic concurrency: version naik di setiap UPDATE (ETag / If-Match)
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- termasuk perubahan stok & soft delete, sama seperti updated_at
+CREATE TRIGGER trg_products_version
+    BEFORE UPDATE ON products
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER trg_categories_version
+    BEFORE UPDATE ON categories
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
@@ -1,7 +1,36 @@
 package model
 
+import "time"
+
 type Category struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	ParentID    *int       `json:"parent_id"`            // NULL = root category
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // soft delete
+	Version     int        `json:"version"`              // naik tiap update, dipakai sebagai ETag
+
+	// tree response only (GET /categories/tree)
+	Children []Category `json:"children,omitempty"`
+}
+
+// =====================================================
+// DELETE /categories/{id}?policy=
+// block  : tolak kalau masih ada produk / subcategory (default)
+// cascade: soft delete category + semua turunan + produknya
+// move   : produk pindah ke target_category_id,
+// subcategory naik ke parent category yang dihapus
+// =====================================================
+type CategoryDeletePolicy string
+
+const (
+	CategoryDeleteBlock   CategoryDeletePolicy = "block"
+	CategoryDeleteCascade CategoryDeletePolicy = "cascade"
+	CategoryDeleteMove    CategoryDeletePolicy = "move"
+)
+
+type CategoryDeleteRequest struct {
+	Policy           CategoryDeletePolicy
+	TargetCategoryID *int // wajib untuk policy move
+	Version          int  // dari If-Match, 0 = tidak dicek
 }
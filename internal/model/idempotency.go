@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// =====================================================
+// Idempotency Key (POST /checkout retry)
+// table: idempotency_keys
+// =====================================================
+type IdempotencyKey struct {
+	Key           string    `json:"key"`
+	RequestHash   string    `json:"request_hash"` // sha256 body checkout
+	TransactionID int       `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
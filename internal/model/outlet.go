@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// =====================================================
+// Outlet (toko / gudang)
+// table: outlets
+// - satu outlet default: dipakai kalau outlet_id tidak dikirim
+// =====================================================
+type Outlet struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Active    bool      `json:"active"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// =====================================================
+// Outlet Stock (stok per outlet)
+// table: outlet_stock
+// products.stok / product_variants.stok = total semua outlet
+// =====================================================
+type OutletStock struct {
+	OutletID   int               `json:"outlet_id"`
+	ProductID  int               `json:"product_id"`
+	VariantID  *int              `json:"variant_id,omitempty"`
+	Nama       string            `json:"nama"`
+	Attributes map[string]string `json:"attributes,omitempty"` // attributes varian
+	Stok       int               `json:"stok"`
+}
+
+// =====================================================
+// Stock Transfer (antar outlet)
+// table: stock_transfers, stock_transfer_items
+// in_transit → received / cancelled
+// =====================================================
+type StockTransfer struct {
+	ID           int                 `json:"id"`
+	FromOutletID int                 `json:"from_outlet_id"`
+	ToOutletID   int                 `json:"to_outlet_id"`
+	Status       string              `json:"status"`
+	Note         string              `json:"note"`
+	UserID       *int                `json:"user_id"`
+	CreatedAt    time.Time           `json:"created_at"`
+	ReceivedAt   *time.Time          `json:"received_at,omitempty"`
+	CancelledAt  *time.Time          `json:"cancelled_at,omitempty"`
+	Items        []StockTransferItem `json:"items"`
+}
+
+type StockTransferItem struct {
+	ID         int  `json:"id"`
+	TransferID int  `json:"transfer_id"`
+	ProductID  int  `json:"product_id"`
+	VariantID  *int `json:"variant_id,omitempty"`
+	Quantity   int  `json:"quantity"`
+}
+
+// stock_transfers.status
+const (
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
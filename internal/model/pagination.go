@@ -0,0 +1,30 @@
+package model
+
+// =====================================================
+// Page Request (query param ?limit=&offset=&cursor=&sort=&order=)
+// - cursor diisi → keyset pagination (offset diabaikan)
+// - limit 0 → tanpa limit (hanya untuk pemanggilan internal)
+// (NOT a database table)
+// =====================================================
+type PageRequest struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   string
+	Desc   bool
+}
+
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Total      int    `json:"total"`
+	Sort       string `json:"sort"`
+	Order      string `json:"order"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// response list endpoint: { "data": [...], "pagination": {...} }
+type Page[T any] struct {
+	Data       []T      `json:"data"`
+	Pagination PageInfo `json:"pagination"`
+}
@@ -1,11 +1,131 @@
 package model
 
+import "time"
+
 type Product struct {
-	ID           int    `json:"id"`
-	Nama         string `json:"nama"`
-	Harga        int    `json:"harga"`
-	Stok         int    `json:"stok"`
-	Active       bool   `json:"active"`
-	CategoryID   int    `json:"category_id"`
-	CategoryName string `json:"category_name,omitempty"` // JOIN result
+	ID           int        `json:"id"`
+	SKU          string     `json:"sku,omitempty"`
+	Nama         string     `json:"nama"`
+	Harga        int        `json:"harga"`
+	HargaPokok   *int       `json:"harga_pokok"` // HPP; diupdate weighted-average dari purchase order, PUT tanpa field = tidak diubah
+	Stok         int        `json:"stok"`
+	Active       bool       `json:"active"`
+	CategoryID   int        `json:"category_id"`
+	CategoryName string     `json:"category_name,omitempty"` // JOIN result
+	Barcodes     []string   `json:"barcodes"`                // GTIN-13; PUT tanpa field barcodes = barcode lama tetap
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"` // soft delete
+	Version      int        `json:"version"`              // naik tiap update, dipakai sebagai ETag
+
+	Variants []ProductVariant `json:"variants,omitempty"`
+}
+
+// =====================================================
+// Product filter (query param GET /product)
+// semua field optional, dikombinasikan dengan AND
+// (NOT a database table)
+// =====================================================
+type ProductFilter struct {
+	Name                 string
+	Active               *bool
+	CategoryIDs          []int
+	IncludeSubcategories bool // category_ids + semua turunannya
+
+	MinHarga *int
+	MaxHarga *int
+	MinStok  *int
+	MaxStok  *int
+
+	InStock           *bool // true: stok > 0, false: stok = 0
+	LowStockThreshold *int  // stok <= threshold
+
+	CreatedFrom *time.Time // inclusive
+	CreatedTo   *time.Time // exclusive
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+
+	IncludeDeleted bool // admin only: ikutkan produk yang sudah di-soft delete
+}
+
+// true kalau tidak ada kriteria sama sekali (= semua produk)
+// IncludeSubcategories & IncludeDeleted hanya pengubah, bukan kriteria
+func (f ProductFilter) IsEmpty() bool {
+	return f.Name == "" &&
+		f.Active == nil &&
+		len(f.CategoryIDs) == 0 &&
+		f.MinHarga == nil && f.MaxHarga == nil &&
+		f.MinStok == nil && f.MaxStok == nil &&
+		f.InStock == nil &&
+		f.LowStockThreshold == nil &&
+		f.CreatedFrom == nil && f.CreatedTo == nil &&
+		f.UpdatedFrom == nil && f.UpdatedTo == nil
+}
+
+// =====================================================
+// Product import (POST /product/import, CSV / JSON lines)
+// - upsert by sku, atau by nama kalau sku kosong / belum ada
+// - kategori by nama, dibuat kalau belum ada
+// - field optional nil = default (create) / tidak diubah (update)
+// (NOT a database table)
+// =====================================================
+type ProductImportRow struct {
+	Line       int      `json:"-"` // nomor baris di file (1-based)
+	SKU        string   `json:"sku"`
+	Nama       string   `json:"nama"`
+	Kategori   string   `json:"kategori"`
+	Harga      int      `json:"harga"`
+	HargaPokok *int     `json:"harga_pokok"`
+	Stok       *int     `json:"stok"` // stok total, selisih dicatat di outlet default
+	Active     *bool    `json:"active"`
+	Barcodes   []string `json:"barcodes"`
+
+	// baris tidak bisa di-parse (angka salah, JSON rusak, ...)
+	ParseError string `json:"-"`
+}
+
+type ProductImportResult struct {
+	DryRun            bool                 `json:"dry_run"`
+	Total             int                  `json:"total"`
+	Created           int                  `json:"created"`
+	Updated           int                  `json:"updated"`
+	Failed            int                  `json:"failed"`
+	CategoriesCreated []string             `json:"categories_created"`
+	Errors            []ProductImportError `json:"errors"`
+}
+
+type ProductImportError struct {
+	Line    int    `json:"line"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// =====================================================
+// Bulk update (PATCH /product/bulk)
+// - seleksi: ids di body dan/atau filter query string GET /product (AND)
+// - operasi yang nil tidak dijalankan
+// (NOT a database table)
+// =====================================================
+type ProductBulkUpdate struct {
+	IDs        []int        `json:"ids"`
+	Harga      *PriceChange `json:"harga"`
+	Active     *bool        `json:"active"`
+	CategoryID *int         `json:"category_id"`
+
+	// dari query string, nil = tanpa filter
+	Filter *ProductFilter `json:"-"`
+}
+
+// isi tepat satu: set (harga baru), add (+/- rupiah) atau percent (+/- persen)
+// add / percent juga berlaku untuk harga varian, set hanya harga produk
+type PriceChange struct {
+	Set     *int     `json:"set,omitempty"`
+	Add     *int     `json:"add,omitempty"`
+	Percent *float64 `json:"percent,omitempty"`
+}
+
+type ProductBulkResult struct {
+	Affected         int `json:"affected"`
+	VariantsAffected int `json:"variants_affected"`
 }
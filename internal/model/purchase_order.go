@@ -0,0 +1,106 @@
+package model
+
+import "time"
+
+// =====================================================
+// Supplier
+// table: suppliers
+// =====================================================
+type Supplier struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	Address   string    `json:"address"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// =====================================================
+// Purchase Order (header)
+// table: purchase_orders
+// draft → ordered → partially_received → received
+// =====================================================
+type PurchaseOrder struct {
+	ID           int                 `json:"id"`
+	SupplierID   int                 `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name,omitempty"` // JOIN result
+	OutletID     int                 `json:"outlet_id"`               // outlet penerima, 0 = outlet default
+	Status       string              `json:"status"`
+	Note         string              `json:"note"`
+	TotalCost    int                 `json:"total_cost"` // SUM(quantity * unit_cost)
+	UserID       *int                `json:"user_id"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	OrderedAt    *time.Time          `json:"ordered_at,omitempty"`
+	ReceivedAt   *time.Time          `json:"received_at,omitempty"`
+	Items        []PurchaseOrderItem `json:"items"`
+}
+
+// purchase_orders.status
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderOrdered           = "ordered"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+// =====================================================
+// Purchase Order filter (query param GET /purchase-orders)
+// (NOT a database table)
+// =====================================================
+type PurchaseOrderFilter struct {
+	Status     string
+	SupplierID *int
+}
+
+// =====================================================
+// Purchase Order Item
+// table: purchase_order_items
+// =====================================================
+type PurchaseOrderItem struct {
+	ID               int    `json:"id"`
+	PurchaseOrderID  int    `json:"purchase_order_id"`
+	ProductID        int    `json:"product_id"`
+	VariantID        *int   `json:"variant_id,omitempty"`
+	ProductName      string `json:"product_name,omitempty"` // JOIN result
+	Quantity         int    `json:"quantity"`
+	UnitCost         int    `json:"unit_cost"`
+	ReceivedQuantity int    `json:"received_quantity"`
+}
+
+// =====================================================
+// Receive Request DTO (POST /purchase-orders/{id}/receive)
+// items kosong = terima semua sisa item
+// (NOT a database table)
+// =====================================================
+type ReceiveRequest struct {
+	Items []ReceiveRequestItem `json:"items"`
+}
+
+type ReceiveRequestItem struct {
+	ItemID   int `json:"item_id"` // purchase_order_items.id
+	Quantity int `json:"quantity"`
+}
+
+// =====================================================
+// Product Cost History (weighted-average cost)
+// table: product_cost_history
+// cost_after = (cost_before * stock_before + unit_cost * quantity)
+// / (stock_before + quantity)
+// purchase_order_id NULL = HPP diset manual
+// =====================================================
+type ProductCost struct {
+	ID              int       `json:"id"`
+	ProductID       int       `json:"product_id"`
+	VariantID       *int      `json:"variant_id,omitempty"`
+	PurchaseOrderID *int      `json:"purchase_order_id"`
+	Quantity        int       `json:"quantity"`
+	UnitCost        int       `json:"unit_cost"`
+	StockBefore     int       `json:"stock_before"`
+	CostBefore      int       `json:"cost_before"`
+	CostAfter       int       `json:"cost_after"`
+	CreatedAt       time.Time `json:"created_at"`
+}
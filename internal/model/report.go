@@ -1,12 +1,100 @@
 package model
 
+import "time"
+
+// =====================================================
+// Report filter (query param /report)
+// (NOT a database table)
+// =====================================================
+// handler mengisi Start / End dengan tanggal (00:00 UTC),
+// service mengubahnya ke batas hari bisnis di Location + DayCutoff
+type ReportFilter struct {
+	Start     time.Time      // inclusive
+	End       time.Time      // exclusive
+	OutletID  *int           // nil = semua outlet
+	TopN      int            // jumlah produk di top_by_qty / top_by_revenue
+	Location  *time.Location // nil = timezone bisnis (config)
+	DayCutoff time.Duration  // diisi service dari config
+}
+
 type BestSeller struct {
 	Nama       string `json:"nama"`
 	QtyTerjual int    `json:"qty_terjual"`
 }
 
+// peringkat produk (qty & revenue net refund);
+// seri diurutkan: metrik lain desc, lalu product_id asc
+type ProductRank struct {
+	ProductID  int    `json:"product_id"`
+	Nama       string `json:"nama"`
+	QtyTerjual int    `json:"qty_terjual"`
+	Revenue    int    `json:"revenue"`
+}
+
+// penjualan per hari; hari tanpa transaksi tetap muncul dengan nilai 0
+type DailySales struct {
+	Tanggal        string `json:"tanggal"` // YYYY-MM-DD
+	Revenue        int    `json:"revenue"` // net of refunds
+	TotalRefund    int    `json:"total_refund"`
+	TotalTransaksi int    `json:"total_transaksi"`
+	QtyTerjual     int    `json:"qty_terjual"`
+}
+
+// penjualan per produk (qty, revenue & COGS net refund),
+// varian di-roll up ke produk induknya
+type ProductSales struct {
+	ProductID   int            `json:"product_id"`
+	Nama        string         `json:"nama"`
+	QtyTerjual  int            `json:"qty_terjual"`
+	Revenue     int            `json:"revenue"`
+	COGS        int            `json:"cogs"`
+	GrossProfit int            `json:"gross_profit"`
+	Margin      float64        `json:"margin"` // persen dari revenue
+	Variants    []VariantSales `json:"variants,omitempty"`
+}
+
+type VariantSales struct {
+	VariantID   int               `json:"variant_id"`
+	Attributes  map[string]string `json:"attributes"`
+	QtyTerjual  int               `json:"qty_terjual"`
+	Revenue     int               `json:"revenue"`
+	COGS        int               `json:"cogs"`
+	GrossProfit int               `json:"gross_profit"`
+	Margin      float64           `json:"margin"`
+}
+
+// penjualan per kategori (snapshot kategori saat checkout),
+// category_id nil = produk tanpa kategori
+type CategorySales struct {
+	CategoryID  *int    `json:"category_id"`
+	Nama        string  `json:"nama"`
+	QtyTerjual  int     `json:"qty_terjual"`
+	Revenue     int     `json:"revenue"`
+	COGS        int     `json:"cogs"`
+	GrossProfit int     `json:"gross_profit"`
+	Margin      float64 `json:"margin"`
+}
+
+type PaymentSummary struct {
+	Method          string `json:"method"`
+	Total           int    `json:"total"`
+	JumlahTransaksi int    `json:"jumlah_transaksi"`
+}
+
 type ReportResponse struct {
-	TotalRevenue   int        `json:"total_revenue"`
-	TotalTransaksi int        `json:"total_transaksi"`
-	ProdukTerlaris BestSeller `json:"produk_terlaris"`
+	TotalRevenue   int              `json:"total_revenue"` // net of refunds
+	TotalRefund    int              `json:"total_refund"`
+	COGS           int              `json:"cogs"` // HPP snapshot, net of refunds
+	GrossProfit    int              `json:"gross_profit"`
+	Margin         float64          `json:"margin"` // persen dari total_revenue
+	TotalTransaksi int              `json:"total_transaksi"`
+	RataRataBasket float64          `json:"rata_rata_basket"`   // total_revenue / total_transaksi
+	ItemPerTrx     float64          `json:"item_per_transaksi"` // qty terjual / total_transaksi
+	ProdukTerlaris BestSeller       `json:"produk_terlaris"`    // = top_by_qty[0]
+	TopByQty       []ProductRank    `json:"top_by_qty"`
+	TopByRevenue   []ProductRank    `json:"top_by_revenue"`
+	Payments       []PaymentSummary `json:"payments"`
+	Kategori       []CategorySales  `json:"kategori"`
+	Produk         []ProductSales   `json:"produk"`
+	Harian         []DailySales     `json:"harian"`
 }
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// =====================================================
+// Stock Movement (ledger, append-only)
+// table: stock_movements
+// - quantity: perubahan stok (+ masuk / - keluar)
+// - stok produk = SUM(quantity) semua movement
+// - stock_after: stok di outlet movement tersebut
+// =====================================================
+type StockMovement struct {
+	ID          int       `json:"id"`
+	ProductID   int       `json:"product_id"`
+	VariantID   *int      `json:"variant_id,omitempty"` // NULL = stok produk tanpa varian
+	OutletID    int       `json:"outlet_id"`
+	Type        string    `json:"type"`
+	Quantity    int       `json:"quantity"`
+	StockAfter  int       `json:"stock_after"`
+	Reason      string    `json:"reason"`
+	ReferenceID *int      `json:"reference_id"` // transaction / refund id, dll
+	UserID      *int      `json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// stock_movements.type
+const (
+	StockMovementSale        = "sale"
+	StockMovementRefund      = "refund"
+	StockMovementAdjustment  = "adjustment"
+	StockMovementReceiving   = "receiving"
+	StockMovementStocktake   = "stocktake"
+	StockMovementTransferOut = "transfer_out"
+	StockMovementTransferIn  = "transfer_in"
+)
+
+// =====================================================
+// Stock Adjustment Request DTO (POST /product/{id}/stock)
+// - adjustment: quantity = selisih (+/-)
+// - receiving : quantity = jumlah barang masuk (> 0)
+// - stocktake : quantity = hasil hitung fisik (stok baru di outlet)
+// (NOT a database table)
+// =====================================================
+type StockAdjustmentRequest struct {
+	VariantID   *int   `json:"variant_id"` // wajib untuk produk yang punya varian
+	OutletID    int    `json:"outlet_id"`  // 0 = outlet default
+	Type        string `json:"type"`
+	Quantity    int    `json:"quantity"`
+	Reason      string `json:"reason"`
+	ReferenceID *int   `json:"reference_id"`
+}
+
+// =====================================================
+// Stock Drift (hasil consistency check)
+// (NOT a database table)
+// =====================================================
+type StockDrift struct {
+	ProductID  int    `json:"product_id"`
+	VariantID  *int   `json:"variant_id,omitempty"`
+	Nama       string `json:"nama"`
+	Stok       int    `json:"stok"`        // products.stok / product_variants.stok
+	LedgerStok int    `json:"ledger_stok"` // SUM(stock_movements.quantity)
+	Drift      int    `json:"drift"`       // stok - ledger_stok
+}
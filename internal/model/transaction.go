@@ -7,34 +7,175 @@ import "time"
 // table: transactions
 // =====================================================
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	CreatedAt   time.Time           `json:"created_at"`
-	Details     []TransactionDetail `json:"details"`
+	ID           int                 `json:"id"`
+	TotalAmount  int                 `json:"total_amount"`
+	PaidAmount   int                 `json:"paid_amount"`
+	ChangeAmount int                 `json:"change_amount"` // kembalian (cash)
+	Status       string              `json:"status"`
+	OutletID     int                 `json:"outlet_id"`
+	UserID       *int                `json:"user_id"` // kasir yang checkout
+	CreatedAt    time.Time           `json:"created_at"`
+	Details      []TransactionDetail `json:"details"`
+	Payments     []Payment           `json:"payments,omitempty"`
+	Refunds      []Refund            `json:"refunds,omitempty"`
+
+	// true kalau response diambil dari Idempotency-Key yang sudah ada
+	Replayed bool `json:"-"`
 }
 
+// transactions.status
+const (
+	TransactionStatusCompleted         = "completed"
+	TransactionStatusPartiallyRefunded = "partially_refunded"
+	TransactionStatusRefunded          = "refunded"
+	TransactionStatusVoided            = "voided"
+)
+
 // =====================================================
 // Transaction Detail (items)
 // table: transaction_details
+// unit_price, unit_cost, product_name, category = snapshot saat checkout
+// (tidak ikut berubah kalau produk di-rename / dihapus)
 // =====================================================
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name,omitempty"` // derived (JOIN products)
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID                int               `json:"id"`
+	TransactionID     int               `json:"transaction_id"`
+	ProductID         int               `json:"product_id"`
+	ProductName       string            `json:"product_name"`
+	VariantID         *int              `json:"variant_id,omitempty"`
+	VariantAttributes map[string]string `json:"variant_attributes,omitempty"` // snapshot attributes varian
+	CategoryID        *int              `json:"category_id"`
+	CategoryName      string            `json:"category_name"`
+	UnitPrice         int               `json:"unit_price"`
+	UnitCost          int               `json:"unit_cost"` // snapshot harga_pokok produk
+	Quantity          int               `json:"quantity"`
+	Subtotal          int               `json:"subtotal"`
+}
+
+// =====================================================
+// Transaction export line (GET /transactions?format=csv|xlsx)
+// satu baris = satu detail line + header transaksinya
+// (NOT a database table)
+// =====================================================
+type TransactionExportLine struct {
+	TransactionID int
+	CreatedAt     time.Time
+	Status        string
+	OutletID      int
+	OutletName    string
+	Cashier       string // username kasir, kosong kalau user sudah dihapus
+	TotalAmount   int
+	Payments      string // mis. "cash:50000 qris:20000" (amount, bukan tendered)
+	Detail        TransactionDetail
+	RefundedQty   int
+	RefundedTotal int
 }
 
 // =====================================================
 // Checkout Request DTO
 // (NOT a database table)
 // =====================================================
+// produk dipilih lewat salah satu: product_id (+ variant_id), variant_id,
+// barcode (EAN-13 / UPC-A), atau sku (produk / varian)
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID int    `json:"product_id,omitempty"`
+	VariantID int    `json:"variant_id,omitempty"`
+	Barcode   string `json:"barcode,omitempty"`
+	SKU       string `json:"sku,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+type CheckoutPayment struct {
+	Method string `json:"method"` // cash | card | qris | ewallet
+	Amount int    `json:"amount"` // nominal yang diserahkan customer
 }
 
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	OutletID int               `json:"outlet_id,omitempty"` // 0 = outlet default
+	Items    []CheckoutItem    `json:"items"`
+	Payments []CheckoutPayment `json:"payments"`
+
+	// dari header Idempotency-Key (bukan body)
+	Idempotency *IdempotencyKey `json:"-"`
+}
+
+// =====================================================
+// Payment (tender)
+// table: transaction_payments
+// - tendered: uang yang diserahkan
+// - amount  : yang dipakai untuk membayar (tendered - kembalian)
+// =====================================================
+type Payment struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	Method        string `json:"method"`
+	Amount        int    `json:"amount"`
+	Tendered      int    `json:"tendered"`
+}
+
+// transaction_payments.method
+const (
+	PaymentMethodCash    = "cash"
+	PaymentMethodCard    = "card"
+	PaymentMethodQRIS    = "qris"
+	PaymentMethodEWallet = "ewallet"
+)
+
+func IsValidPaymentMethod(method string) bool {
+	switch method {
+	case PaymentMethodCash, PaymentMethodCard, PaymentMethodQRIS, PaymentMethodEWallet:
+		return true
+	}
+	return false
+}
+
+// =====================================================
+// Refund / Void (header)
+// table: refunds
+// =====================================================
+type Refund struct {
+	ID            int          `json:"id"`
+	TransactionID int          `json:"transaction_id"`
+	Type          string       `json:"type"` // refund | void
+	Reason        string       `json:"reason"`
+	TotalAmount   int          `json:"total_amount"`
+	UserID        *int         `json:"user_id"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Items         []RefundItem `json:"items"`
+}
+
+// refunds.type
+const (
+	RefundTypeRefund = "refund"
+	RefundTypeVoid   = "void"
+)
+
+// =====================================================
+// Refund Item (lines)
+// table: refund_items
+// =====================================================
+type RefundItem struct {
+	ID                  int `json:"id"`
+	RefundID            int `json:"refund_id"`
+	TransactionDetailID int `json:"transaction_detail_id"`
+	ProductID           int `json:"product_id"`
+	Quantity            int `json:"quantity"`
+	Amount              int `json:"amount"`
+}
+
+// =====================================================
+// Refund Request DTO
+// items kosong = refund semua sisa item
+// item dipilih via transaction_detail_id ATAU product_id
+// (NOT a database table)
+// =====================================================
+type RefundRequestItem struct {
+	TransactionDetailID int `json:"transaction_detail_id"`
+	ProductID           int `json:"product_id"`
+	Quantity            int `json:"quantity"`
+}
+
+type RefundRequest struct {
+	Reason string              `json:"reason"`
+	Items  []RefundRequestItem `json:"items"`
 }
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// =====================================================
+// User (login kasir / manager / admin)
+// table: users
+// =====================================================
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// users.role
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleCashier = "cashier"
+)
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleManager, RoleCashier:
+		return true
+	}
+	return false
+}
+
+// =====================================================
+// Auth DTO
+// (NOT a database table)
+// =====================================================
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // detik, access token
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// =====================================================
+// Product Variant (ukuran / rasa)
+// table: product_variants
+// harga, stok, sku sendiri; stok varian punya ledger sendiri
+// (stock_movements.variant_id).
+// produk yang punya varian dijual per varian
+// =====================================================
+type ProductVariant struct {
+	ID         int               `json:"id"`
+	ProductID  int               `json:"product_id"`
+	SKU        string            `json:"sku,omitempty"`
+	Attributes map[string]string `json:"attributes"` // {"size": "L", "flavor": "pedas"}
+	Harga      int               `json:"harga"`
+	Stok       int               `json:"stok"`
+	Active     bool              `json:"active"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
@@ -3,17 +3,37 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"time"
 
+	"github.com/jackyansen22/crud-category/internal/apperror"
 	"github.com/jackyansen22/crud-category/internal/model"
 )
 
+var (
+	ErrCategoryNotFound = apperror.NotFound("CATEGORY_NOT_FOUND", "category not found")
+	ErrParentNotFound   = apperror.Validation("PARENT_CATEGORY_NOT_FOUND", "parent category not found")
+	ErrCategoryCycle    = apperror.Conflict("CATEGORY_CYCLE", "category cannot be moved under itself or one of its descendants")
+	ErrCategoryInUse    = apperror.Conflict("CATEGORY_IN_USE", "category still has products or subcategories")
+
+	ErrCategoryNotDeleted  = apperror.Conflict("CATEGORY_NOT_DELETED", "category is not deleted")
+	ErrParentDeleted       = apperror.Conflict("PARENT_CATEGORY_DELETED", "parent category is deleted, restore it first")
+	ErrInvalidDeleteTarget = apperror.Validation("INVALID_TARGET_CATEGORY", "target category must be another existing category")
+)
+
+// advisory lock key untuk serialisasi perubahan tree (parent_id)
+const categoryTreeLockKey = 7301
+
 type CategoryRepository interface {
-	FindAll(ctx context.Context) ([]model.Category, error)
+	FindAll(ctx context.Context, page model.PageRequest, includeDeleted bool) (*model.Page[model.Category], error)
 	FindByID(ctx context.Context, id int) (*model.Category, error)
+	FindSubtree(ctx context.Context, id int) ([]model.Category, error)
+	FindAncestors(ctx context.Context, id int) ([]model.Category, error)
 	Create(ctx context.Context, c *model.Category) error
 	Update(ctx context.Context, c *model.Category) error
-	Delete(ctx context.Context, id int) error
+	Move(ctx context.Context, id int, parentID *int) error
+	Delete(ctx context.Context, id int, req model.CategoryDeleteRequest) error
+	Restore(ctx context.Context, id int) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
 type categoryRepository struct {
@@ -24,40 +44,85 @@ func NewCategoryRepository(db *sql.DB) CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
-func (r *categoryRepository) FindAll(ctx context.Context) ([]model.Category, error) {
+var categorySort = sortSpec{
+	fields: map[string]sortField{
+		"id":   {column: "id", sqlType: "integer"},
+		"name": {column: "name", sqlType: "text"},
+	},
+	idColumn:    "id",
+	defaultSort: "id",
+}
+
+func (r *categoryRepository) FindAll(
+	ctx context.Context,
+	page model.PageRequest,
+	includeDeleted bool,
+) (*model.Page[model.Category], error) {
+
+	pc, err := buildPage(page, categorySort, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	where := " AND deleted_at IS NULL"
+	if includeDeleted {
+		where = ""
+	}
+
+	var total int
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM categories
+		WHERE 1=1`+where,
+	).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, name, description
+		SELECT id, name, description, parent_id, deleted_at, version, `+pc.sortExpr+`
 		FROM categories
-		ORDER BY id
-	`)
+		WHERE 1=1`+where+pc.where+pc.orderBy,
+		pc.args...,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var categories []model.Category
+	var (
+		categories []model.Category
+		sortValues []string
+	)
 	for rows.Next() {
-		var c model.Category
-		if err := rows.Scan(&c.ID, &c.Name, &c.Description); err != nil {
+		var (
+			c         model.Category
+			sortValue string
+		)
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.ParentID, &c.DeletedAt, &c.Version, &sortValue); err != nil {
 			return nil, err
 		}
 		categories = append(categories, c)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return categories, nil
+	return newPage(pc, categories, sortValues, total, func(c model.Category) int { return c.ID }), nil
 }
 
 func (r *categoryRepository) FindByID(ctx context.Context, id int) (*model.Category, error) {
 	var c model.Category
 
 	err := r.db.QueryRowContext(ctx, `
-		SELECT id, name, description
+		SELECT id, name, description, parent_id, version
 		FROM categories
-		WHERE id = $1
-	`, id).Scan(&c.ID, &c.Name, &c.Description)
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id).Scan(&c.ID, &c.Name, &c.Description, &c.ParentID, &c.Version)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("category not found")
+		return nil, ErrCategoryNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -66,47 +131,481 @@ func (r *categoryRepository) FindByID(ctx context.Context, id int) (*model.Categ
 	return &c, nil
 }
 
+// =====================================================
+// SUBTREE: category itu sendiri + semua turunannya
+// (urut per level, root subtree paling atas)
+// =====================================================
+func (r *categoryRepository) FindSubtree(ctx context.Context, id int) ([]model.Category, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT id, name, description, parent_id, version, 0 AS depth
+			FROM categories
+			WHERE id = $1 AND deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT c.id, c.name, c.description, c.parent_id, c.version, s.depth + 1
+			FROM categories c
+			JOIN subtree s ON c.parent_id = s.id
+			WHERE c.deleted_at IS NULL
+		)
+		SELECT id, name, description, parent_id, version
+		FROM subtree
+		ORDER BY depth, id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories, err := scanCategories(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return nil, ErrCategoryNotFound
+	}
+
+	return categories, nil
+}
+
+// =====================================================
+// ANCESTORS: dari root sampai parent langsung
+// (category itu sendiri tidak ikut)
+// =====================================================
+func (r *categoryRepository) FindAncestors(ctx context.Context, id int) ([]model.Category, error) {
+	if _, err := r.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT p.id, p.name, p.description, p.parent_id, p.version, 1 AS depth
+			FROM categories c
+			JOIN categories p ON p.id = c.parent_id
+			WHERE c.id = $1
+
+			UNION ALL
+
+			SELECT p.id, p.name, p.description, p.parent_id, p.version, a.depth + 1
+			FROM categories p
+			JOIN ancestors a ON p.id = a.parent_id
+		)
+		SELECT id, name, description, parent_id, version
+		FROM ancestors
+		ORDER BY depth DESC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCategories(rows)
+}
+
 func (r *categoryRepository) Create(ctx context.Context, c *model.Category) error {
+	if c.ParentID != nil {
+		var exists bool
+		err := r.db.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL)
+		`, *c.ParentID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrParentNotFound
+		}
+	}
+
 	return r.db.QueryRowContext(ctx, `
-		INSERT INTO categories (name, description)
-		VALUES ($1, $2)
-		RETURNING id
-	`, c.Name, c.Description).Scan(&c.ID)
+		INSERT INTO categories (name, description, parent_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, version
+	`, c.Name, c.Description, c.ParentID).Scan(&c.ID, &c.Version)
 }
 
 func (r *categoryRepository) Update(ctx context.Context, c *model.Category) error {
-	res, err := r.db.ExecContext(ctx, `
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// advisory lock tree dulu (urutan lock sama dengan Delete)
+	if err := checkCategoryParent(ctx, tx, c.ID, c.ParentID); err != nil {
+		return err
+	}
+
+	var version int
+	err = tx.QueryRowContext(ctx, `
+		SELECT version
+		FROM categories
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, c.ID).Scan(&version)
+	if err == sql.ErrNoRows {
+		return ErrCategoryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(c.Version, version); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
 		UPDATE categories
-		SET name = $1, description = $2
-		WHERE id = $3
-	`, c.Name, c.Description, c.ID)
+		SET name = $1, description = $2, parent_id = $3
+		WHERE id = $4
+		RETURNING version
+	`, c.Name, c.Description, c.ParentID, c.ID).Scan(&c.Version)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// =====================================================
+// MOVE NODE: pindah ke parent lain (nil = jadi root)
+// =====================================================
+func (r *categoryRepository) Move(ctx context.Context, id int, parentID *int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkCategoryParent(ctx, tx, id, parentID); err != nil {
+		return err
+	}
 
+	res, err := tx.ExecContext(ctx, `
+		UPDATE categories
+		SET parent_id = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`, parentID, id)
 	if err != nil {
 		return err
 	}
 
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
-		return errors.New("category not found")
+		return ErrCategoryNotFound
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-func (r *categoryRepository) Delete(ctx context.Context, id int) error {
-	res, err := r.db.ExecContext(ctx, `
-		DELETE FROM categories
+// =====================================================
+// SOFT DELETE (lihat model.CategoryDeletePolicy)
+// semua row yang ikut terhapus dapat deleted_at yang sama (NOW() per tx),
+// supaya Restore bisa mengembalikan hasil cascade sekaligus
+// =====================================================
+func (r *categoryRepository) Delete(
+	ctx context.Context,
+	id int,
+	req model.CategoryDeleteRequest,
+) error {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLockKey); err != nil {
+		return err
+	}
+
+	var (
+		parentID *int
+		version  int
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT parent_id, version
+		FROM categories
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id).Scan(&parentID, &version)
+	if err == sql.ErrNoRows {
+		return ErrCategoryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(req.Version, version); err != nil {
+		return err
+	}
+
+	switch req.Policy {
+	case model.CategoryDeleteCascade:
+		_, err = tx.ExecContext(ctx, liveSubtreeCTE+`
+			UPDATE products
+			SET deleted_at = NOW()
+			WHERE deleted_at IS NULL
+			  AND category_id IN (SELECT id FROM subtree)
+		`, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, liveSubtreeCTE+`
+			UPDATE categories
+			SET deleted_at = NOW()
+			WHERE id IN (SELECT id FROM subtree)
+		`, id)
+		if err != nil {
+			return err
+		}
+
+		return tx.Commit()
+
+	case model.CategoryDeleteMove:
+		target := req.TargetCategoryID
+		if target == nil || *target == id {
+			return ErrInvalidDeleteTarget
+		}
+
+		var exists bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL)
+		`, *target).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrInvalidDeleteTarget
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE products
+			SET category_id = $1
+			WHERE category_id = $2 AND deleted_at IS NULL
+		`, *target, id)
+		if err != nil {
+			return err
+		}
+
+		// subcategory naik satu level
+		_, err = tx.ExecContext(ctx, `
+			UPDATE categories
+			SET parent_id = $1
+			WHERE parent_id = $2 AND deleted_at IS NULL
+		`, parentID, id)
+		if err != nil {
+			return err
+		}
+
+	default:
+		var inUse bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT
+				EXISTS (SELECT 1 FROM products WHERE category_id = $1 AND deleted_at IS NULL)
+				OR EXISTS (SELECT 1 FROM categories WHERE parent_id = $1 AND deleted_at IS NULL)
+		`, id).Scan(&inUse)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return ErrCategoryInUse
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE categories
+		SET deleted_at = NOW()
 		WHERE id = $1
 	`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// category + turunan yang masih aktif ($1 = root)
+const liveSubtreeCTE = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM categories WHERE id = $1 AND deleted_at IS NULL
+
+		UNION ALL
 
+		SELECT c.id
+		FROM categories c
+		JOIN subtree s ON c.parent_id = s.id
+		WHERE c.deleted_at IS NULL
+	)
+`
+
+// =====================================================
+// RESTORE
+// ikut mengembalikan subcategory + produk yang terhapus
+// bersamaan (cascade, deleted_at sama)
+// =====================================================
+func (r *categoryRepository) Restore(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return errors.New("category not found")
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLockKey); err != nil {
+		return err
+	}
+
+	var (
+		parentID  *int
+		deletedAt *time.Time
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT parent_id, deleted_at
+		FROM categories
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&parentID, &deletedAt)
+	if err == sql.ErrNoRows {
+		return ErrCategoryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if deletedAt == nil {
+		return ErrCategoryNotDeleted
+	}
+
+	if parentID != nil {
+		var parentAlive bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL)
+		`, *parentID).Scan(&parentAlive)
+		if err != nil {
+			return err
+		}
+		if !parentAlive {
+			return ErrParentDeleted
+		}
+	}
+
+	const cascadeSubtree = `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+
+			UNION ALL
+
+			SELECT c.id
+			FROM categories c
+			JOIN subtree s ON c.parent_id = s.id
+			WHERE c.deleted_at = $2
+		)
+	`
+
+	_, err = tx.ExecContext(ctx, cascadeSubtree+`
+		UPDATE products
+		SET deleted_at = NULL
+		WHERE deleted_at = $2
+		  AND category_id IN (SELECT id FROM subtree)
+	`, id, *deletedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, cascadeSubtree+`
+		UPDATE categories
+		SET deleted_at = NULL
+		WHERE id IN (SELECT id FROM subtree)
+	`, id, *deletedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// =====================================================
+// PURGE: hard delete category yang di-soft delete sebelum `before`
+// dan sudah tidak punya produk / subcategory (termasuk yang soft delete).
+// diulang supaya parent ikut terhapus setelah child-nya
+// =====================================================
+func (r *categoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		res, err := r.db.ExecContext(ctx, `
+			DELETE FROM categories c
+			WHERE c.deleted_at < $1
+			  AND NOT EXISTS (SELECT 1 FROM products p WHERE p.category_id = c.id)
+			  AND NOT EXISTS (SELECT 1 FROM categories ch WHERE ch.parent_id = c.id)
+		`, before)
+		if err != nil {
+			return total, err
+		}
+
+		n, _ := res.RowsAffected()
+		if n == 0 {
+			return total, nil
+		}
+		total += n
+	}
+}
+
+// =====================================================
+// CYCLE PREVENTION
+// - tree lock supaya dua move paralel tidak bikin cycle
+// - parent harus ada
+// - parent tidak boleh category itu sendiri / turunannya
+// =====================================================
+func checkCategoryParent(ctx context.Context, tx *sql.Tx, id int, parentID *int) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLockKey); err != nil {
+		return err
+	}
+
+	if parentID == nil {
+		return nil
+	}
+
+	var exists bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL)
+	`, *parentID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrParentNotFound
+	}
+
+	var cycle bool
+	err = tx.QueryRowContext(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+
+			UNION ALL
+
+			SELECT c.id
+			FROM categories c
+			JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)
+	`, id, *parentID).Scan(&cycle)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return ErrCategoryCycle
 	}
 
 	return nil
 }
+
+func scanCategories(rows *sql.Rows) ([]model.Category, error) {
+	var categories []model.Category
+	for rows.Next() {
+		var c model.Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.ParentID, &c.Version); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+var (
+	ErrOutletNotFound        = apperror.NotFound("OUTLET_NOT_FOUND", "outlet not found")
+	ErrOutletInactive        = apperror.Unprocessable("OUTLET_INACTIVE", "outlet is inactive")
+	ErrOutletNameTaken       = apperror.Conflict("OUTLET_NAME_TAKEN", "outlet name already used")
+	ErrDefaultOutletInactive = apperror.Conflict("DEFAULT_OUTLET_INACTIVE", "default outlet cannot be deactivated")
+)
+
+type OutletRepository interface {
+	FindAll(ctx context.Context) ([]model.Outlet, error)
+	FindByID(ctx context.Context, id int) (*model.Outlet, error)
+	FindStock(ctx context.Context, id int) ([]model.OutletStock, error)
+	Create(ctx context.Context, o *model.Outlet) error
+	Update(ctx context.Context, o *model.Outlet) error
+}
+
+type outletRepository struct {
+	db *sql.DB
+}
+
+func NewOutletRepository(db *sql.DB) OutletRepository {
+	return &outletRepository{db: db}
+}
+
+func (r *outletRepository) FindAll(ctx context.Context) ([]model.Outlet, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, address, active, is_default, created_at, updated_at
+		FROM outlets
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outlets := make([]model.Outlet, 0)
+	for rows.Next() {
+		var o model.Outlet
+		if err := rows.Scan(
+			&o.ID,
+			&o.Name,
+			&o.Address,
+			&o.Active,
+			&o.IsDefault,
+			&o.CreatedAt,
+			&o.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, o)
+	}
+
+	return outlets, rows.Err()
+}
+
+func (r *outletRepository) FindByID(ctx context.Context, id int) (*model.Outlet, error) {
+	var o model.Outlet
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, address, active, is_default, created_at, updated_at
+		FROM outlets
+		WHERE id = $1
+	`, id).Scan(
+		&o.ID,
+		&o.Name,
+		&o.Address,
+		&o.Active,
+		&o.IsDefault,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrOutletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// =====================================================
+// STOK PER OUTLET
+// produk / varian yang sudah di-soft delete tidak ditampilkan
+// =====================================================
+func (r *outletRepository) FindStock(ctx context.Context, id int) ([]model.OutletStock, error) {
+	if _, err := r.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.outlet_id, s.product_id, s.variant_id, p.nama, v.attributes, s.stok
+		FROM outlet_stock s
+		JOIN products p ON p.id = s.product_id
+		LEFT JOIN product_variants v ON v.id = s.variant_id
+		WHERE s.outlet_id = $1
+		  AND p.deleted_at IS NULL
+		  AND v.deleted_at IS NULL
+		ORDER BY p.nama, s.product_id, s.variant_id NULLS FIRST
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stock := make([]model.OutletStock, 0)
+	for rows.Next() {
+		var (
+			s          model.OutletStock
+			attributes []byte
+		)
+		if err := rows.Scan(
+			&s.OutletID,
+			&s.ProductID,
+			&s.VariantID,
+			&s.Nama,
+			&attributes,
+			&s.Stok,
+		); err != nil {
+			return nil, err
+		}
+		if attributes != nil {
+			if err := json.Unmarshal(attributes, &s.Attributes); err != nil {
+				return nil, err
+			}
+		}
+		stock = append(stock, s)
+	}
+
+	return stock, rows.Err()
+}
+
+func (r *outletRepository) Create(ctx context.Context, o *model.Outlet) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO outlets (name, address, active)
+		VALUES ($1, $2, $3)
+		RETURNING id, is_default, created_at, updated_at
+	`,
+		o.Name,
+		o.Address,
+		o.Active,
+	).Scan(&o.ID, &o.IsDefault, &o.CreatedAt, &o.UpdatedAt)
+
+	if isUniqueViolation(err) {
+		return ErrOutletNameTaken
+	}
+	return err
+}
+
+// =====================================================
+// UPDATE OUTLET
+// - is_default true: outlet default lama dilepas
+// - outlet default tidak boleh dinonaktifkan
+// =====================================================
+func (r *outletRepository) Update(ctx context.Context, o *model.Outlet) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var isDefault bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT is_default
+		FROM outlets
+		WHERE id = $1
+		FOR UPDATE
+	`, o.ID).Scan(&isDefault)
+
+	if err == sql.ErrNoRows {
+		return ErrOutletNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	o.IsDefault = o.IsDefault || isDefault
+	if o.IsDefault && !o.Active {
+		return ErrDefaultOutletInactive
+	}
+
+	if o.IsDefault && !isDefault {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE outlets
+			SET is_default = FALSE
+			WHERE is_default
+		`)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE outlets
+		SET name = $1,
+		    address = $2,
+		    active = $3,
+		    is_default = $4
+		WHERE id = $5
+		RETURNING created_at, updated_at
+	`,
+		o.Name,
+		o.Address,
+		o.Active,
+		o.IsDefault,
+		o.ID,
+	).Scan(&o.CreatedAt, &o.UpdatedAt)
+
+	if isUniqueViolation(err) {
+		return ErrOutletNameTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// =====================================================
+// OUTLET HELPER (dipakai repository lain dalam sql.Tx)
+// outletID 0 = outlet default; outlet nonaktif ditolak
+// =====================================================
+func resolveOutlet(ctx context.Context, tx *sql.Tx, outletID int) (int, error) {
+	var active bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, active
+		FROM outlets
+		WHERE ($1 = 0 AND is_default) OR id = $1
+	`, outletID).Scan(&outletID, &active)
+
+	if err == sql.ErrNoRows {
+		return 0, ErrOutletNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !active {
+		return 0, ErrOutletInactive.WithDetails(map[string]int{"outlet_id": outletID})
+	}
+
+	return outletID, nil
+}
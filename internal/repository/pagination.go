@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+var (
+	ErrInvalidSort   = apperror.Validation("INVALID_SORT", "invalid sort field")
+	ErrInvalidCursor = apperror.Validation("INVALID_CURSOR", "invalid cursor")
+)
+
+// kolom yang boleh dipakai untuk ?sort=
+type sortField struct {
+	column  string // ekspresi SQL, mis. "p.harga"
+	sqlType string // untuk cast nilai cursor, mis. "integer"
+}
+
+type sortSpec struct {
+	fields      map[string]sortField
+	idColumn    string // tie-breaker, harus unik
+	defaultSort string
+	defaultDesc bool
+}
+
+// isi cursor (base64url JSON)
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// =====================================================
+// PAGE CLAUSE
+// - where   : kondisi keyset ("" kalau tanpa cursor), diawali " AND "
+// - orderBy : ORDER BY + LIMIT/OFFSET (ambil limit+1 untuk cek next page)
+// - sortExpr: kolom sort sebagai text, di-select untuk bikin next cursor
+// =====================================================
+type pageClause struct {
+	where    string
+	orderBy  string
+	sortExpr string
+	args     []any
+	req      model.PageRequest
+}
+
+func buildPage(req model.PageRequest, spec sortSpec, argPos int) (*pageClause, error) {
+	if req.Sort == "" {
+		req.Sort = spec.defaultSort
+		req.Desc = spec.defaultDesc
+	}
+
+	field, ok := spec.fields[req.Sort]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrInvalidSort, req.Sort)
+	}
+
+	dir, cmp := "ASC", ">"
+	if req.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	pc := &pageClause{
+		sortExpr: field.column + "::text",
+		req:      req,
+	}
+
+	if req.Cursor != "" {
+		c, err := decodeCursor(req.Cursor)
+		if err != nil || c.Sort != req.Sort || c.Desc != req.Desc {
+			return nil, ErrInvalidCursor
+		}
+
+		pc.where = fmt.Sprintf(
+			" AND (%s, %s) %s ($%d::%s, $%d::integer)",
+			field.column, spec.idColumn, cmp,
+			argPos, field.sqlType, argPos+1,
+		)
+		pc.args = append(pc.args, c.Value, c.ID)
+		argPos += 2
+		pc.req.Offset = 0
+	}
+
+	pc.orderBy = fmt.Sprintf(" ORDER BY %s %s, %s %s", field.column, dir, spec.idColumn, dir)
+
+	if req.Limit > 0 {
+		pc.orderBy += " LIMIT $" + strconv.Itoa(argPos)
+		pc.args = append(pc.args, req.Limit+1)
+		argPos++
+	}
+	if pc.req.Offset > 0 {
+		pc.orderBy += " OFFSET $" + strconv.Itoa(argPos)
+		pc.args = append(pc.args, pc.req.Offset)
+	}
+
+	return pc, nil
+}
+
+// =====================================================
+// PAGE RESULT
+// items & sortValues hasil query (limit+1 baris),
+// id(item) dipakai sebagai tie-breaker di cursor
+// =====================================================
+func newPage[T any](
+	pc *pageClause,
+	items []T,
+	sortValues []string,
+	total int,
+	id func(T) int,
+) *model.Page[T] {
+
+	page := &model.Page[T]{
+		Data: items,
+		Pagination: model.PageInfo{
+			Limit:  pc.req.Limit,
+			Offset: pc.req.Offset,
+			Total:  total,
+			Sort:   pc.req.Sort,
+			Order:  "asc",
+		},
+	}
+	if pc.req.Desc {
+		page.Pagination.Order = "desc"
+	}
+
+	if pc.req.Limit > 0 && len(items) > pc.req.Limit {
+		page.Data = items[:pc.req.Limit]
+
+		last := page.Data[len(page.Data)-1]
+		page.Pagination.NextCursor = encodeCursor(pageCursor{
+			Sort:  pc.req.Sort,
+			Desc:  pc.req.Desc,
+			Value: sortValues[pc.req.Limit-1],
+			ID:    id(last),
+		})
+	}
+
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+
+	return page
+}
+
+func encodeCursor(c pageCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+
+	return c, err
+}
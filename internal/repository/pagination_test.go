@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+var testSort = sortSpec{
+	fields: map[string]sortField{
+		"id":    {column: "id", sqlType: "integer"},
+		"harga": {column: "p.harga", sqlType: "integer"},
+	},
+	idColumn:    "id",
+	defaultSort: "id",
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := pageCursor{Sort: "harga", Desc: true, Value: "12500", ID: 42}
+
+	s := encodeCursor(want)
+	if strings.ContainsAny(s, "+/=") {
+		t.Errorf("cursor %q is not url-safe", s)
+	}
+
+	got, err := decodeCursor(s)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestBuildPageCursor(t *testing.T) {
+	valid := encodeCursor(pageCursor{Sort: "harga", Value: "12500", ID: 42})
+
+	tests := []struct {
+		name      string
+		req       model.PageRequest
+		wantWhere string
+		wantOrder string
+		wantArgs  []any
+		wantErr   error
+	}{
+		{
+			name:      "default sort, limit + 1",
+			req:       model.PageRequest{Limit: 20},
+			wantOrder: " ORDER BY id ASC, id ASC LIMIT $1",
+			wantArgs:  []any{21},
+		},
+		{
+			name:      "offset tanpa cursor",
+			req:       model.PageRequest{Limit: 10, Offset: 30, Sort: "harga", Desc: true},
+			wantOrder: " ORDER BY p.harga DESC, id DESC LIMIT $1 OFFSET $2",
+			wantArgs:  []any{11, 30},
+		},
+		{
+			name:      "cursor valid, offset diabaikan",
+			req:       model.PageRequest{Limit: 10, Offset: 30, Sort: "harga", Cursor: valid},
+			wantWhere: " AND (p.harga, id) > ($1::integer, $2::integer)",
+			wantOrder: " ORDER BY p.harga ASC, id ASC LIMIT $3",
+			wantArgs:  []any{"12500", 42, 11},
+		},
+		{
+			name:    "sort tidak dikenal",
+			req:     model.PageRequest{Sort: "nama; DROP TABLE products"},
+			wantErr: ErrInvalidSort,
+		},
+		{
+			name:    "cursor bukan base64",
+			req:     model.PageRequest{Sort: "harga", Cursor: "%%%not-base64"},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "cursor base64 tapi bukan JSON",
+			req:     model.PageRequest{Sort: "harga", Cursor: base64.RawURLEncoding.EncodeToString([]byte("garbage"))},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "cursor dari sort lain",
+			req:     model.PageRequest{Sort: "id", Cursor: valid},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "cursor dari order lain",
+			req:     model.PageRequest{Sort: "harga", Desc: true, Cursor: valid},
+			wantErr: ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc, err := buildPage(tt.req, testSort, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if pc.where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", pc.where, tt.wantWhere)
+			}
+			if pc.orderBy != tt.wantOrder {
+				t.Errorf("orderBy = %q, want %q", pc.orderBy, tt.wantOrder)
+			}
+			if !reflect.DeepEqual(pc.args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", pc.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestNewPageNextCursor(t *testing.T) {
+	pc, err := buildPage(model.PageRequest{Limit: 2, Sort: "harga"}, testSort, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := func(n int) int { return n }
+
+	// limit+1 baris → ada halaman berikutnya
+	page := newPage(pc, []int{7, 8, 9}, []string{"100", "200", "300"}, 3, id)
+	if !reflect.DeepEqual(page.Data, []int{7, 8}) {
+		t.Errorf("data = %v, want [7 8]", page.Data)
+	}
+	c, err := decodeCursor(page.Pagination.NextCursor)
+	if err != nil {
+		t.Fatalf("next cursor: %v", err)
+	}
+	if want := (pageCursor{Sort: "harga", Value: "200", ID: 8}); c != want {
+		t.Errorf("next cursor = %+v, want %+v", c, want)
+	}
+
+	// halaman terakhir → tanpa cursor, data kosong tetap []
+	page = newPage(pc, []int(nil), nil, 0, id)
+	if page.Pagination.NextCursor != "" || page.Data == nil {
+		t.Errorf("last page = %+v", page)
+	}
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// postgres error code (https://www.postgresql.org/docs/current/errcodes-appendix.html)
+const pqUniqueViolation = "23505"
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
+
+// nama constraint yang dilanggar, "" kalau bukan unique violation
+func uniqueViolationConstraint(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+		return pqErr.Constraint
+	}
+	return ""
+}
@@ -5,17 +5,39 @@ import (
 	"database/sql"
 	"errors"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackyansen22/crud-category/internal/apperror"
 	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrProductNotDeleted   = apperror.Conflict("PRODUCT_NOT_DELETED", "product is not deleted")
+	ErrCategoryDeleted     = apperror.Conflict("CATEGORY_DELETED", "product category is deleted, restore it first")
+	ErrSKUTaken            = apperror.Conflict("SKU_TAKEN", "sku already used by another product")
+	ErrBarcodeTaken        = apperror.Conflict("BARCODE_TAKEN", "barcode already used by another product")
+	ErrImportNameAmbiguous = apperror.Conflict("IMPORT_NAME_AMBIGUOUS", "more than one product has this name, use sku")
+	ErrBulkNegativeHarga   = apperror.Unprocessable("BULK_NEGATIVE_HARGA", "price change would make harga negative")
+
+	// category_id di body tidak ada → 400, bukan 404 (resource-nya product)
+	ErrInvalidCategory = apperror.Validation("INVALID_CATEGORY", "category not found")
 )
 
 type ProductRepository interface {
-	FindAll(ctx context.Context) ([]model.Product, error)
-	FindByFilter(ctx context.Context, name string, active *bool) ([]model.Product, error)
+	FindByFilter(ctx context.Context, f model.ProductFilter, page model.PageRequest) (*model.Page[model.Product], error)
+	ExportByFilter(ctx context.Context, f model.ProductFilter, fn func(model.Product) error) error
 	FindByID(ctx context.Context, id int) (*model.Product, error)
+	FindByBarcode(ctx context.Context, code string) (*model.Product, error)
+	FindCostHistory(ctx context.Context, id int) ([]model.ProductCost, error)
 	Create(ctx context.Context, p *model.Product) error
 	Update(ctx context.Context, p *model.Product) error
-	Delete(ctx context.Context, id int) error
+	Import(ctx context.Context, rows []model.ProductImportRow, dryRun bool) (*model.ProductImportResult, error)
+	BulkUpdate(ctx context.Context, u model.ProductBulkUpdate) (*model.ProductBulkResult, error)
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 	CategoryExists(ctx context.Context, categoryID int) bool
 }
 
@@ -27,105 +49,247 @@ func NewProductRepository(db *sql.DB) ProductRepository {
 	return &productRepository{db: db}
 }
 
+var productSort = sortSpec{
+	fields: map[string]sortField{
+		"id":         {column: "id", sqlType: "integer"},
+		"nama":       {column: "nama", sqlType: "text"},
+		"harga":      {column: "harga", sqlType: "integer"},
+		"stok":       {column: "stok", sqlType: "integer"},
+		"created_at": {column: "created_at", sqlType: "timestamptz"},
+		"updated_at": {column: "updated_at", sqlType: "timestamptz"},
+	},
+	idColumn:    "id",
+	defaultSort: "id",
+}
+
 // =====================================================
-// GET ALL PRODUCTS
+// GET PRODUCTS WITH FILTER + PAGINATION
+// (lihat model.ProductFilter)
 // =====================================================
-func (r *productRepository) FindAll(
+func (r *productRepository) FindByFilter(
 	ctx context.Context,
-) ([]model.Product, error) {
+	f model.ProductFilter,
+	page model.PageRequest,
+) (*model.Page[model.Product], error) {
+
+	where := productFilterWhere(f)
+	args := where.args
+	argPos := where.nextPos()
+
+	pc, err := buildPage(page, productSort, argPos)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM products
+		WHERE 1=1`+where.String(),
+		args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
 
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
 			id,
+			COALESCE(sku, ''),
 			nama,
 			harga,
+			harga_pokok,
 			stok,
 			active,
-			category_id
+			category_id,
+			created_at,
+			updated_at,
+			deleted_at,
+			version,
+			`+pc.sortExpr+`
 		FROM products
-		ORDER BY id
-	`)
+		WHERE 1=1`+where.String()+pc.where+pc.orderBy,
+		append(args, pc.args...)...,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var products []model.Product
+	var (
+		products   []model.Product
+		sortValues []string
+	)
 	for rows.Next() {
-		var p model.Product
+		var (
+			p         model.Product
+			sortValue string
+		)
 		if err := rows.Scan(
 			&p.ID,
+			&p.SKU,
 			&p.Nama,
 			&p.Harga,
+			&p.HargaPokok,
 			&p.Stok,
 			&p.Active,
 			&p.CategoryID, // ✅ WAJIB
+			&p.CreatedAt,
+			&p.UpdatedAt,
+			&p.DeletedAt,
+			&p.Version,
+			&sortValue,
 		); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
+		sortValues = append(sortValues, sortValue)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
 
-	return products, nil
+	if err := r.attachBarcodes(ctx, products); err != nil {
+		return nil, err
+	}
+	if err := attachVariants(ctx, r.db, products); err != nil {
+		return nil, err
+	}
+
+	return newPage(pc, products, sortValues, total, func(p model.Product) int { return p.ID }), nil
 }
 
 // =====================================================
-// GET PRODUCTS WITH FILTER (?name=&active=)
+// EXPORT PRODUCTS (streaming, filter sama dengan GET /product)
+// - tanpa pagination, urut id
+// - kategori & barcode diambil per baris (varian tidak ikut)
 // =====================================================
-func (r *productRepository) FindByFilter(
+func (r *productRepository) ExportByFilter(
 	ctx context.Context,
-	name string,
-	active *bool,
-) ([]model.Product, error) {
+	f model.ProductFilter,
+	fn func(model.Product) error,
+) error {
+
+	where := productFilterWhere(f)
 
-	query := `
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT
 			id,
+			COALESCE(sku, ''),
 			nama,
 			harga,
+			harga_pokok,
 			stok,
 			active,
-			category_id
+			category_id,
+			COALESCE((SELECT c.name FROM categories c WHERE c.id = products.category_id), ''),
+			ARRAY(SELECT b.code FROM product_barcodes b WHERE b.product_id = products.id ORDER BY b.id),
+			created_at,
+			updated_at,
+			deleted_at,
+			version
 		FROM products
-		WHERE 1=1
-	`
-	args := []any{}
-	argPos := 1
-
-	if name != "" {
-		query += " AND LOWER(nama) LIKE LOWER($" + strconv.Itoa(argPos) + ")"
-		args = append(args, "%"+name+"%")
-		argPos++
-	}
-
-	if active != nil {
-		query += " AND active = $" + strconv.Itoa(argPos)
-		args = append(args, *active)
-	}
-
-	rows, err := r.db.QueryContext(ctx, query, args...)
+		WHERE 1=1`+where.String()+`
+		ORDER BY id`,
+		where.args...,
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var products []model.Product
 	for rows.Next() {
 		var p model.Product
 		if err := rows.Scan(
 			&p.ID,
+			&p.SKU,
 			&p.Nama,
 			&p.Harga,
+			&p.HargaPokok,
 			&p.Stok,
 			&p.Active,
-			&p.CategoryID, // ✅ WAJIB
+			&p.CategoryID,
+			&p.CategoryName,
+			pq.Array(&p.Barcodes),
+			&p.CreatedAt,
+			&p.UpdatedAt,
+			&p.DeletedAt,
+			&p.Version,
 		); err != nil {
-			return nil, err
+			return err
 		}
-		products = append(products, p)
+
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func productFilterWhere(f model.ProductFilter) *whereBuilder {
+	w := &whereBuilder{}
+
+	if !f.IncludeDeleted {
+		w.add("deleted_at IS NULL")
+	}
+	if f.Name != "" {
+		w.add("LOWER(nama) LIKE LOWER(?)", "%"+f.Name+"%")
+	}
+	if f.Active != nil {
+		w.add("active = ?", *f.Active)
+	}
+
+	if len(f.CategoryIDs) > 0 && f.IncludeSubcategories {
+		w.add(`category_id IN (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM categories WHERE id = ANY(?)
+				UNION ALL
+				SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+			)
+			SELECT id FROM subtree
+		)`, pq.Array(f.CategoryIDs))
+	} else if len(f.CategoryIDs) > 0 {
+		w.add("category_id = ANY(?)", pq.Array(f.CategoryIDs))
+	}
+
+	if f.MinHarga != nil {
+		w.add("harga >= ?", *f.MinHarga)
+	}
+	if f.MaxHarga != nil {
+		w.add("harga <= ?", *f.MaxHarga)
+	}
+	if f.MinStok != nil {
+		w.add("stok >= ?", *f.MinStok)
+	}
+	if f.MaxStok != nil {
+		w.add("stok <= ?", *f.MaxStok)
+	}
+
+	if f.InStock != nil && *f.InStock {
+		w.add("stok > 0")
+	} else if f.InStock != nil {
+		w.add("stok <= 0")
+	}
+	if f.LowStockThreshold != nil {
+		w.add("stok <= ?", *f.LowStockThreshold)
+	}
+
+	if f.CreatedFrom != nil {
+		w.add("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		w.add("created_at < ?", *f.CreatedTo)
+	}
+	if f.UpdatedFrom != nil {
+		w.add("updated_at >= ?", *f.UpdatedFrom)
+	}
+	if f.UpdatedTo != nil {
+		w.add("updated_at < ?", *f.UpdatedTo)
 	}
 
-	return products, nil
+	return w
 }
 
 // =====================================================
@@ -140,106 +304,719 @@ func (r *productRepository) FindByID(
 	err := r.db.QueryRowContext(ctx, `
 		SELECT
 			p.id,
+			COALESCE(p.sku, ''),
 			p.nama,
 			p.harga,
+			p.harga_pokok,
 			p.stok,
 			p.active,
 			p.category_id,
-			c.name
+			c.name,
+			p.created_at,
+			p.updated_at,
+			p.version
 		FROM products p
 		JOIN categories c ON c.id = p.category_id
-		WHERE p.id = $1
+		WHERE p.id = $1 AND p.deleted_at IS NULL
 	`, id).Scan(
 		&p.ID,
+		&p.SKU,
 		&p.Nama,
 		&p.Harga,
+		&p.HargaPokok,
 		&p.Stok,
 		&p.Active,
 		&p.CategoryID,
 		&p.CategoryName,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.Version,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("product not found")
+		return nil, ErrProductNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &p, nil
+	products := []model.Product{p}
+	if err := r.attachBarcodes(ctx, products); err != nil {
+		return nil, err
+	}
+	if err := attachVariants(ctx, r.db, products); err != nil {
+		return nil, err
+	}
+
+	return &products[0], nil
+}
+
+// =====================================================
+// GET PRODUCT BY BARCODE (code sudah GTIN-13)
+// =====================================================
+func (r *productRepository) FindByBarcode(ctx context.Context, code string) (*model.Product, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT b.product_id
+		FROM product_barcodes b
+		JOIN products p ON p.id = b.product_id
+		WHERE b.code = $1 AND p.deleted_at IS NULL
+	`, code).Scan(&id)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, id)
 }
 
 // =====================================================
 // CREATE PRODUCT
 // =====================================================
+// stok awal dicatat ke ledger sebagai receiving
 func (r *productRepository) Create(
 	ctx context.Context,
 	p *model.Product,
 ) error {
-	return r.db.QueryRowContext(ctx, `
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createProduct(ctx, tx, p); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createProduct(ctx context.Context, tx *sql.Tx, p *model.Product) error {
+	err := tx.QueryRowContext(ctx, `
 		INSERT INTO products
-			(nama, harga, stok, active, category_id)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
+			(sku, nama, harga, harga_pokok, active, category_id)
+		VALUES (NULLIF($1, ''), $2, $3, COALESCE($4, 0), $5, $6)
+		RETURNING id, harga_pokok, created_at, updated_at, version
 	`,
+		p.SKU,
 		p.Nama,
 		p.Harga,
-		p.Stok,
+		p.HargaPokok,
 		p.Active,
 		p.CategoryID,
-	).Scan(&p.ID)
+	).Scan(&p.ID, &p.HargaPokok, &p.CreatedAt, &p.UpdatedAt, &p.Version)
+	if err != nil {
+		return productUniqueError(err)
+	}
+
+	if p.Barcodes == nil {
+		p.Barcodes = []string{}
+	}
+	if err := replaceBarcodes(ctx, tx, p.ID, p.Barcodes); err != nil {
+		return err
+	}
+
+	// stok awal masuk ke outlet default
+	if p.Stok != 0 {
+		err = applyStockChange(ctx, tx, &model.StockMovement{
+			ProductID: p.ID,
+			Type:      model.StockMovementReceiving,
+			Quantity:  p.Stok,
+			Reason:    "initial stock",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // =====================================================
 // UPDATE PRODUCT
+// - perubahan stok dicatat ke ledger sebagai adjustment
+// - perubahan harga_pokok manual dicatat ke cost history
+// - p.Version != 0 = harus sama dengan version sekarang (If-Match)
 // =====================================================
 func (r *productRepository) Update(ctx context.Context, p *model.Product) error {
-	res, err := r.db.ExecContext(ctx, `
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateProduct(ctx, tx, p); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func updateProduct(ctx context.Context, tx *sql.Tx, p *model.Product) error {
+	// 🔒 lock product row
+	var stock, cost, categoryID, version int
+	err := tx.QueryRowContext(ctx, `
+		SELECT stok, harga_pokok, category_id, version
+		FROM products
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, p.ID).Scan(&stock, &cost, &categoryID, &version)
+
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(p.Version, version); err != nil {
+		return err
+	}
+
+	// category_id 0 (field tidak dikirim) = kategori lama
+	// pindah kategori: kategori tujuan harus ada & tidak di-soft delete
+	// (FOR SHARE supaya tidak dihapus sebelum commit)
+	if p.CategoryID == 0 {
+		p.CategoryID = categoryID
+	}
+	if p.CategoryID != categoryID {
+		var found int
+		err := tx.QueryRowContext(ctx, `
+			SELECT id FROM categories
+			WHERE id = $1 AND deleted_at IS NULL
+			FOR SHARE
+		`, p.CategoryID).Scan(&found)
+
+		if err == sql.ErrNoRows {
+			return ErrInvalidCategory
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
 		UPDATE products
-		SET nama = $1,
-		    harga = $2,
-		    stok = $3,
-		    active = $4
-		WHERE id = $5
+		SET sku = NULLIF($1, ''),
+		    nama = $2,
+		    harga = $3,
+		    active = $4,
+		    category_id = $5
+		WHERE id = $6
 	`,
+		p.SKU,
 		p.Nama,
 		p.Harga,
-		p.Stok,
 		p.Active,
+		p.CategoryID,
 		p.ID,
 	)
-
 	if err != nil {
-		return err
+		return productUniqueError(err)
 	}
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return errors.New("product not found")
+	// harga_pokok nil (field tidak dikirim) = tidak diubah
+	if p.HargaPokok != nil && *p.HargaPokok != cost {
+		if err := setCostPrice(ctx, tx, p.ID, stock, cost, *p.HargaPokok); err != nil {
+			return err
+		}
+	}
+
+	// barcodes nil (field tidak dikirim) = tidak diubah
+	if p.Barcodes != nil {
+		if err := replaceBarcodes(ctx, tx, p.ID, p.Barcodes); err != nil {
+			return err
+		}
+	}
+
+	// selisih stok total dicatat di outlet default
+	if p.Stok != stock {
+		err = applyStockChange(ctx, tx, &model.StockMovement{
+			ProductID: p.ID,
+			Type:      model.StockMovementAdjustment,
+			Quantity:  p.Stok - stock,
+			Reason:    "product update",
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 // =====================================================
-// DELETE PRODUCT
+// IMPORT PRODUCTS (POST /product/import)
+//   - semua baris dalam satu sql.Tx, tiap baris pakai SAVEPOINT
+//     supaya error satu baris tidak menghentikan pengecekan baris lain
+//   - dry run / ada baris error → rollback semua
+//
 // =====================================================
-func (r *productRepository) Delete(ctx context.Context, id int) error {
-	res, err := r.db.ExecContext(ctx, `
-		DELETE FROM products
+func (r *productRepository) Import(
+	ctx context.Context,
+	rows []model.ProductImportRow,
+	dryRun bool,
+) (*model.ProductImportResult, error) {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &model.ProductImportResult{
+		DryRun:            dryRun,
+		Total:             len(rows),
+		CategoriesCreated: []string{},
+		Errors:            []model.ProductImportError{},
+	}
+	categories := make(map[string]int) // LOWER(name) → id
+
+	for i := range rows {
+		row := &rows[i]
+
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+			return nil, err
+		}
+
+		created, category, err := importProduct(ctx, tx, row, categories)
+
+		// error bisnis (validasi / conflict) = error baris, selain itu batalkan import
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_row`); err != nil {
+				return nil, err
+			}
+			if category != "" {
+				delete(categories, strings.ToLower(category))
+			}
+			result.Errors = append(result.Errors, model.ProductImportError{
+				Line:    row.Line,
+				Code:    appErr.Code,
+				Message: appErr.Error(),
+				Details: appErr.Details,
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT import_row`); err != nil {
+			return nil, err
+		}
+
+		if category != "" {
+			result.CategoriesCreated = append(result.CategoriesCreated, category)
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	result.Failed = len(result.Errors)
+
+	if dryRun || result.Failed > 0 {
+		return result, nil
+	}
+	return result, tx.Commit()
+}
+
+// created = false berarti update produk yang sudah ada
+// category = nama kategori yang baru dibuat di baris ini (kosong kalau tidak ada)
+func importProduct(
+	ctx context.Context,
+	tx *sql.Tx,
+	row *model.ProductImportRow,
+	categories map[string]int,
+) (created bool, category string, err error) {
+
+	categoryID, category, err := importCategory(ctx, tx, row.Kategori, categories)
+	if err != nil {
+		return false, category, err
+	}
+
+	p := model.Product{
+		SKU:        row.SKU,
+		Nama:       row.Nama,
+		Harga:      row.Harga,
+		HargaPokok: row.HargaPokok,
+		Active:     true,
+		CategoryID: categoryID,
+		Barcodes:   row.Barcodes,
+	}
+	if row.Active != nil {
+		p.Active = *row.Active
+	}
+	if row.Stok != nil {
+		p.Stok = *row.Stok
+	}
+
+	existing, err := findImportTarget(ctx, tx, row)
+	if err != nil {
+		return false, category, err
+	}
+	if existing == nil {
+		return true, category, createProduct(ctx, tx, &p)
+	}
+
+	// kolom kosong = nilai lama
+	p.ID = existing.ID
+	if row.SKU == "" {
+		p.SKU = existing.SKU
+	}
+	if row.Stok == nil {
+		p.Stok = existing.Stok
+	}
+	if row.Active == nil {
+		p.Active = existing.Active
+	}
+
+	return false, category, updateProduct(ctx, tx, &p)
+}
+
+// kategori dicari by nama (case-insensitive, root dulu), dibuat kalau belum ada
+func importCategory(
+	ctx context.Context,
+	tx *sql.Tx,
+	name string,
+	categories map[string]int,
+) (id int, created string, err error) {
+
+	key := strings.ToLower(name)
+	if id, ok := categories[key]; ok {
+		return id, "", nil
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT id
+		FROM categories
+		WHERE LOWER(name) = LOWER($1) AND deleted_at IS NULL
+		ORDER BY parent_id NULLS FIRST, id
+		LIMIT 1
+	`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO categories (name) VALUES ($1) RETURNING id
+		`, name).Scan(&id)
+		created = name
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	categories[key] = id
+	return id, created, nil
+}
+
+// sku dulu; kalau belum ada, cari by nama di produk yang belum punya sku
+// (baris tanpa sku: by nama di semua produk)
+func findImportTarget(ctx context.Context, tx *sql.Tx, row *model.ProductImportRow) (*model.Product, error) {
+	const query = `
+		SELECT id, COALESCE(sku, ''), stok, active, category_id
+		FROM products
+		WHERE deleted_at IS NULL AND `
+
+	if row.SKU != "" {
+		p, err := scanImportTarget(tx.QueryContext(ctx, query+`sku = $1 FOR UPDATE`, row.SKU))
+		if err != nil || p != nil {
+			return p, err
+		}
+		return scanImportTarget(tx.QueryContext(ctx, query+`sku IS NULL AND LOWER(nama) = LOWER($1) FOR UPDATE`, row.Nama))
+	}
+	return scanImportTarget(tx.QueryContext(ctx, query+`LOWER(nama) = LOWER($1) FOR UPDATE`, row.Nama))
+}
+
+// lebih dari satu produk dengan nama yang sama = ambigu
+func scanImportTarget(rows *sql.Rows, err error) (*model.Product, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found *model.Product
+	for rows.Next() {
+		if found != nil {
+			return nil, ErrImportNameAmbiguous
+		}
+
+		var p model.Product
+		if err := rows.Scan(&p.ID, &p.SKU, &p.Stok, &p.Active, &p.CategoryID); err != nil {
+			return nil, err
+		}
+		found = &p
+	}
+	return found, rows.Err()
+}
+
+// =====================================================
+// BULK UPDATE (PATCH /product/bulk)
+// - satu UPDATE untuk semua produk terpilih (produk terhapus tidak ikut)
+// - harga add / percent ikut diterapkan ke varian aktif
+// - ada harga yang jadi negatif → rollback semua
+// =====================================================
+func (r *productRepository) BulkUpdate(
+	ctx context.Context,
+	u model.ProductBulkUpdate,
+) (*model.ProductBulkResult, error) {
+
+	var f model.ProductFilter
+	if u.Filter != nil {
+		f = *u.Filter
+	}
+	f.IncludeDeleted = false
+
+	where := productFilterWhere(f)
+	if len(u.IDs) > 0 {
+		where.add("id = ANY(?)", pq.Array(u.IDs))
+	}
+
+	var (
+		set  []string
+		args = where.args
+	)
+	param := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	adjust := u.Harga != nil && u.Harga.Set == nil
+	if u.Harga != nil && u.Harga.Set != nil {
+		set = append(set, "harga = "+param(*u.Harga.Set))
+	} else if adjust {
+		value, expr := priceAdjustment(u.Harga)
+		set = append(set, "harga = "+expr(param(value)))
+	}
+	if u.Active != nil {
+		set = append(set, "active = "+param(*u.Active))
+	}
+	if u.CategoryID != nil {
+		set = append(set, "category_id = "+param(*u.CategoryID))
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids, err := bulkUpdateReturning(ctx, tx, `
+		UPDATE products
+		SET `+strings.Join(set, ", ")+`
+		WHERE 1=1`+where.String()+`
+		RETURNING id, harga`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ProductBulkResult{Affected: len(ids)}
+
+	if adjust && len(ids) > 0 {
+		value, expr := priceAdjustment(u.Harga)
+
+		variants, err := bulkUpdateReturning(ctx, tx, `
+			UPDATE product_variants
+			SET harga = `+expr("$2")+`
+			WHERE product_id = ANY($1) AND deleted_at IS NULL
+			RETURNING id, harga`,
+			pq.Array(ids),
+			value,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result.VariantsAffected = len(variants)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// harga add / percent → nilai parameter + ekspresi SQL untuk placeholder p
+func priceAdjustment(c *model.PriceChange) (any, func(p string) string) {
+	if c.Add != nil {
+		return *c.Add, func(p string) string { return "harga + " + p }
+	}
+	return *c.Percent, func(p string) string { return "ROUND(harga * (100 + " + p + "::numeric) / 100)" }
+}
+
+// return id baris yang ter-update; harga negatif → ErrBulkNegativeHarga
+func bulkUpdateReturning(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id, harga int
+		if err := rows.Scan(&id, &harga); err != nil {
+			return nil, err
+		}
+		if harga < 0 {
+			return nil, ErrBulkNegativeHarga
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// =====================================================
+// COST HISTORY (penerimaan purchase order, terbaru di atas)
+// =====================================================
+func (r *productRepository) FindCostHistory(ctx context.Context, id int) ([]model.ProductCost, error) {
+	if err := productExists(ctx, r.db, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			id,
+			product_id,
+			variant_id,
+			purchase_order_id,
+			quantity,
+			unit_cost,
+			stock_before,
+			cost_before,
+			cost_after,
+			created_at
+		FROM product_cost_history
+		WHERE product_id = $1
+		ORDER BY id DESC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]model.ProductCost, 0)
+	for rows.Next() {
+		var c model.ProductCost
+		if err := rows.Scan(
+			&c.ID,
+			&c.ProductID,
+			&c.VariantID,
+			&c.PurchaseOrderID,
+			&c.Quantity,
+			&c.UnitCost,
+			&c.StockBefore,
+			&c.CostBefore,
+			&c.CostAfter,
+			&c.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+
+	return history, rows.Err()
+}
+
+// =====================================================
+// DELETE PRODUCT (soft delete)
+// histori transaksi & ledger tetap utuh, hard delete lewat PurgeDeleted
+// =====================================================
+func (r *productRepository) Delete(ctx context.Context, id int, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current int
+	err = tx.QueryRowContext(ctx, `
+		SELECT version
+		FROM products
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id).Scan(&current)
+
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, current); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE products
+		SET deleted_at = NOW()
 		WHERE id = $1
 	`, id)
+	if err != nil {
+		return err
+	}
 
+	return tx.Commit()
+}
+
+// =====================================================
+// RESTORE PRODUCT
+// category-nya harus aktif
+// =====================================================
+func (r *productRepository) Restore(ctx context.Context, id int) error {
+	var (
+		deletedAt     *time.Time
+		categoryAlive bool
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT p.deleted_at, c.deleted_at IS NULL
+		FROM products p
+		JOIN categories c ON c.id = p.category_id
+		WHERE p.id = $1
+	`, id).Scan(&deletedAt, &categoryAlive)
+
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
 	if err != nil {
 		return err
 	}
+	if deletedAt == nil {
+		return ErrProductNotDeleted
+	}
+	if !categoryAlive {
+		return ErrCategoryDeleted
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE products
+		SET deleted_at = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return errors.New("product not found")
+// =====================================================
+// PURGE: hard delete produk yang di-soft delete sebelum `before`
+// produk yang pernah terjual / ditransfer / ada di purchase order
+// tidak pernah di-purge (histori + FK ON DELETE RESTRICT)
+// =====================================================
+func (r *productRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM products p
+		WHERE p.deleted_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM transaction_details td WHERE td.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM stock_transfer_items sti WHERE sti.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM purchase_order_items poi WHERE poi.product_id = p.id)
+	`, before)
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return res.RowsAffected()
 }
 
 func (r *productRepository) CategoryExists(
@@ -250,7 +1027,7 @@ func (r *productRepository) CategoryExists(
 	var exists bool
 	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
-			SELECT 1 FROM categories WHERE id = $1
+			SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL
 		)
 	`, categoryID).Scan(&exists)
 
@@ -260,3 +1037,79 @@ func (r *productRepository) CategoryExists(
 
 	return exists
 }
+
+// =====================================================
+// BARCODE HELPERS
+// =====================================================
+func replaceBarcodes(ctx context.Context, tx *sql.Tx, productID int, codes []string) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM product_barcodes
+		WHERE product_id = $1
+	`, productID)
+	if err != nil {
+		return err
+	}
+
+	for _, code := range codes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO product_barcodes (product_id, code)
+			VALUES ($1, $2)
+		`, productID, code)
+		if err != nil {
+			return productUniqueError(err)
+		}
+	}
+
+	return nil
+}
+
+// isi Barcodes untuk semua produk sekaligus (satu query)
+func (r *productRepository) attachBarcodes(ctx context.Context, products []model.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(products))
+	ids := make([]int, len(products))
+	for i := range products {
+		products[i].Barcodes = []string{}
+		index[products[i].ID] = i
+		ids[i] = products[i].ID
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT product_id, code
+		FROM product_barcodes
+		WHERE product_id = ANY($1)
+		ORDER BY id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			productID int
+			code      string
+		)
+		if err := rows.Scan(&productID, &code); err != nil {
+			return err
+		}
+		i := index[productID]
+		products[i].Barcodes = append(products[i].Barcodes, code)
+	}
+
+	return rows.Err()
+}
+
+func productUniqueError(err error) error {
+	switch uniqueViolationConstraint(err) {
+	case "products_sku_key":
+		return ErrSKUTaken
+	case "product_barcodes_code_key":
+		return ErrBarcodeTaken
+	default:
+		return err
+	}
+}
@@ -0,0 +1,709 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/auth"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+var (
+	ErrPurchaseOrderNotFound       = apperror.NotFound("PURCHASE_ORDER_NOT_FOUND", "purchase order not found")
+	ErrPurchaseOrderNotDraft       = apperror.Conflict("PURCHASE_ORDER_NOT_DRAFT", "only draft purchase orders can be changed")
+	ErrPurchaseOrderNotReceivable  = apperror.Conflict("PURCHASE_ORDER_NOT_RECEIVABLE", "purchase order is not open for receiving")
+	ErrPurchaseOrderNotCancellable = apperror.Conflict("PURCHASE_ORDER_NOT_CANCELLABLE", "only draft or ordered purchase orders can be cancelled")
+	ErrReceiveItemNotFound         = apperror.Validation("RECEIVE_ITEM_NOT_FOUND", "receive item not found in purchase order")
+	ErrReceiveExceedsOrdered       = apperror.Unprocessable("RECEIVE_EXCEEDS_ORDERED", "received quantity exceeds quantity ordered")
+)
+
+type PurchaseOrderRepository interface {
+	FindAll(ctx context.Context, f model.PurchaseOrderFilter, page model.PageRequest) (*model.Page[model.PurchaseOrder], error)
+	FindByID(ctx context.Context, id int) (*model.PurchaseOrder, error)
+	Create(ctx context.Context, po *model.PurchaseOrder) error
+	Update(ctx context.Context, po *model.PurchaseOrder) error
+	Order(ctx context.Context, id int) error
+	Cancel(ctx context.Context, id int) error
+	Receive(ctx context.Context, id int, req model.ReceiveRequest) error
+}
+
+type purchaseOrderRepository struct {
+	db *sql.DB
+}
+
+func NewPurchaseOrderRepository(db *sql.DB) PurchaseOrderRepository {
+	return &purchaseOrderRepository{db: db}
+}
+
+var purchaseOrderSort = sortSpec{
+	fields: map[string]sortField{
+		"id":         {column: "po.id", sqlType: "integer"},
+		"created_at": {column: "po.created_at", sqlType: "timestamptz"},
+	},
+	idColumn:    "po.id",
+	defaultSort: "created_at",
+	defaultDesc: true,
+}
+
+const purchaseOrderColumns = `
+	po.id,
+	po.supplier_id,
+	s.name,
+	po.outlet_id,
+	po.status,
+	po.note,
+	COALESCE((
+		SELECT SUM(i.quantity * i.unit_cost)
+		FROM purchase_order_items i
+		WHERE i.purchase_order_id = po.id
+	), 0),
+	po.user_id,
+	po.created_at,
+	po.updated_at,
+	po.ordered_at,
+	po.received_at
+`
+
+// list tanpa items (detail lewat FindByID)
+func (r *purchaseOrderRepository) FindAll(
+	ctx context.Context,
+	f model.PurchaseOrderFilter,
+	page model.PageRequest,
+) (*model.Page[model.PurchaseOrder], error) {
+
+	where := &whereBuilder{}
+	if f.Status != "" {
+		where.add("po.status = ?", f.Status)
+	}
+	if f.SupplierID != nil {
+		where.add("po.supplier_id = ?", *f.SupplierID)
+	}
+
+	pc, err := buildPage(page, purchaseOrderSort, where.nextPos())
+	if err != nil {
+		return nil, err
+	}
+
+	var total int
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM purchase_orders po
+		WHERE 1=1`+where.String(),
+		where.args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+purchaseOrderColumns+`, `+pc.sortExpr+`
+		FROM purchase_orders po
+		JOIN suppliers s ON s.id = po.supplier_id
+		WHERE 1=1`+where.String()+pc.where+pc.orderBy,
+		append(where.args, pc.args...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		orders     []model.PurchaseOrder
+		sortValues []string
+	)
+	for rows.Next() {
+		var (
+			po        model.PurchaseOrder
+			sortValue string
+		)
+		if err := rows.Scan(append(purchaseOrderDest(&po), &sortValue)...); err != nil {
+			return nil, err
+		}
+		po.Items = []model.PurchaseOrderItem{}
+
+		orders = append(orders, po)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return newPage(pc, orders, sortValues, total, func(po model.PurchaseOrder) int { return po.ID }), nil
+}
+
+func (r *purchaseOrderRepository) FindByID(ctx context.Context, id int) (*model.PurchaseOrder, error) {
+	var po model.PurchaseOrder
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+purchaseOrderColumns+`
+		FROM purchase_orders po
+		JOIN suppliers s ON s.id = po.supplier_id
+		WHERE po.id = $1
+	`, id).Scan(purchaseOrderDest(&po)...)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			i.id,
+			i.purchase_order_id,
+			i.product_id,
+			i.variant_id,
+			p.nama,
+			i.quantity,
+			i.unit_cost,
+			i.received_quantity
+		FROM purchase_order_items i
+		JOIN products p ON p.id = i.product_id
+		WHERE i.purchase_order_id = $1
+		ORDER BY i.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	po.Items = make([]model.PurchaseOrderItem, 0)
+	for rows.Next() {
+		var item model.PurchaseOrderItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.PurchaseOrderID,
+			&item.ProductID,
+			&item.VariantID,
+			&item.ProductName,
+			&item.Quantity,
+			&item.UnitCost,
+			&item.ReceivedQuantity,
+		); err != nil {
+			return nil, err
+		}
+		po.Items = append(po.Items, item)
+	}
+
+	return &po, rows.Err()
+}
+
+// =====================================================
+// CREATE PURCHASE ORDER (status draft)
+// =====================================================
+func (r *purchaseOrderRepository) Create(ctx context.Context, po *model.PurchaseOrder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkPurchaseOrderHeader(ctx, tx, po); err != nil {
+		return err
+	}
+
+	po.Status = model.PurchaseOrderDraft
+	po.UserID = auth.UserIDFromContext(ctx)
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO purchase_orders (supplier_id, outlet_id, status, note, user_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`,
+		po.SupplierID,
+		po.OutletID,
+		po.Status,
+		po.Note,
+		po.UserID,
+	).Scan(&po.ID, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPurchaseOrderItems(ctx, tx, po); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// =====================================================
+// UPDATE PURCHASE ORDER (hanya draft)
+// items diganti seluruhnya
+// =====================================================
+func (r *purchaseOrderRepository) Update(ctx context.Context, po *model.PurchaseOrder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchaseOrder(ctx, tx, po.ID)
+	if err != nil {
+		return err
+	}
+	if status != model.PurchaseOrderDraft {
+		return ErrPurchaseOrderNotDraft
+	}
+
+	if err := checkPurchaseOrderHeader(ctx, tx, po); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE purchase_orders
+		SET supplier_id = $1,
+		    outlet_id = $2,
+		    note = $3
+		WHERE id = $4
+	`,
+		po.SupplierID,
+		po.OutletID,
+		po.Note,
+		po.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM purchase_order_items
+		WHERE purchase_order_id = $1
+	`, po.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPurchaseOrderItems(ctx, tx, po); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// draft → ordered
+func (r *purchaseOrderRepository) Order(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchaseOrder(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if status != model.PurchaseOrderDraft {
+		return ErrPurchaseOrderNotDraft
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE purchase_orders
+		SET status = $1, ordered_at = NOW()
+		WHERE id = $2
+	`, model.PurchaseOrderOrdered, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// draft / ordered → cancelled (belum ada barang yang diterima)
+func (r *purchaseOrderRepository) Cancel(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchaseOrder(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if status != model.PurchaseOrderDraft && status != model.PurchaseOrderOrdered {
+		return ErrPurchaseOrderNotCancellable.WithDetails(map[string]string{"status": status})
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE purchase_orders
+		SET status = $1
+		WHERE id = $2
+	`, model.PurchaseOrderCancelled, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// =====================================================
+// RECEIVE (penerimaan barang)
+// - satu sql.Tx: lock PO, update HPP, tambah stok (ledger: receiving)
+// - items kosong = terima semua sisa
+// - status: partially_received / received
+// =====================================================
+func (r *purchaseOrderRepository) Receive(
+	ctx context.Context,
+	id int,
+	req model.ReceiveRequest,
+) error {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchaseOrder(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if status != model.PurchaseOrderOrdered && status != model.PurchaseOrderPartiallyReceived {
+		return ErrPurchaseOrderNotReceivable.WithDetails(map[string]string{"status": status})
+	}
+
+	var outletID int
+	err = tx.QueryRowContext(ctx, `
+		SELECT outlet_id FROM purchase_orders WHERE id = $1
+	`, id).Scan(&outletID)
+	if err != nil {
+		return err
+	}
+	if _, err := resolveOutlet(ctx, tx, outletID); err != nil {
+		return err
+	}
+
+	// ==========================
+	// SISA QTY PER ITEM
+	// ==========================
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, product_id, variant_id, quantity, unit_cost, received_quantity
+		FROM purchase_order_items
+		WHERE purchase_order_id = $1
+		ORDER BY id
+		FOR UPDATE
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	var items []model.PurchaseOrderItem
+	for rows.Next() {
+		var item model.PurchaseOrderItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.ProductID,
+			&item.VariantID,
+			&item.Quantity,
+			&item.UnitCost,
+			&item.ReceivedQuantity,
+		); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	received, err := receiveQuantities(items, req.Items)
+	if err != nil {
+		return err
+	}
+
+	// ==========================
+	// HPP + STOK PER ITEM
+	// ==========================
+	complete := true
+	for i := range items {
+		item := &items[i]
+		qty := received[item.ID]
+
+		if qty > 0 {
+			if err := applyCostChange(ctx, tx, id, item, qty); err != nil {
+				return err
+			}
+
+			err = applyStockChange(ctx, tx, &model.StockMovement{
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				OutletID:    outletID,
+				Type:        model.StockMovementReceiving,
+				Quantity:    qty,
+				Reason:      fmt.Sprintf("purchase order #%d", id),
+				ReferenceID: &id,
+			})
+			if err != nil {
+				return err
+			}
+
+			item.ReceivedQuantity += qty
+			_, err = tx.ExecContext(ctx, `
+				UPDATE purchase_order_items
+				SET received_quantity = $1
+				WHERE id = $2
+			`, item.ReceivedQuantity, item.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if item.ReceivedQuantity < item.Quantity {
+			complete = false
+		}
+	}
+
+	if complete {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE purchase_orders
+			SET status = $1, received_at = NOW()
+			WHERE id = $2
+		`, model.PurchaseOrderReceived, id)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE purchase_orders
+			SET status = $1
+			WHERE id = $2
+		`, model.PurchaseOrderPartiallyReceived, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// qty diterima per item id; request kosong = semua sisa
+func receiveQuantities(
+	items []model.PurchaseOrderItem,
+	requested []model.ReceiveRequestItem,
+) (map[int]int, error) {
+
+	received := make(map[int]int, len(items))
+
+	if len(requested) == 0 {
+		for _, item := range items {
+			received[item.ID] = item.Quantity - item.ReceivedQuantity
+		}
+		return received, nil
+	}
+
+	byID := make(map[int]model.PurchaseOrderItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	for _, req := range requested {
+		item, ok := byID[req.ItemID]
+		if !ok {
+			return nil, ErrReceiveItemNotFound.
+				WithMessage(fmt.Sprintf("item %d not found in purchase order", req.ItemID)).
+				WithDetails(map[string]int{"item_id": req.ItemID})
+		}
+
+		// item yang sama boleh muncul lebih dari sekali
+		received[item.ID] += req.Quantity
+		if remaining := item.Quantity - item.ReceivedQuantity; received[item.ID] > remaining {
+			return nil, ErrReceiveExceedsOrdered.
+				WithMessage(fmt.Sprintf(
+					"received quantity exceeds quantity ordered for item %d (remaining %d)",
+					item.ID, remaining,
+				)).
+				WithDetails(map[string]int{
+					"item_id":   item.ID,
+					"remaining": remaining,
+					"requested": received[item.ID],
+				})
+		}
+	}
+
+	return received, nil
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+func purchaseOrderDest(po *model.PurchaseOrder) []any {
+	return []any{
+		&po.ID,
+		&po.SupplierID,
+		&po.SupplierName,
+		&po.OutletID,
+		&po.Status,
+		&po.Note,
+		&po.TotalCost,
+		&po.UserID,
+		&po.CreatedAt,
+		&po.UpdatedAt,
+		&po.OrderedAt,
+		&po.ReceivedAt,
+	}
+}
+
+// 🔒 lock header, return status
+func lockPurchaseOrder(ctx context.Context, tx *sql.Tx, id int) (string, error) {
+	var status string
+	err := tx.QueryRowContext(ctx, `
+		SELECT status
+		FROM purchase_orders
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&status)
+
+	if err == sql.ErrNoRows {
+		return "", ErrPurchaseOrderNotFound
+	}
+	return status, err
+}
+
+// supplier harus aktif, outlet 0 → outlet default
+func checkPurchaseOrderHeader(ctx context.Context, tx *sql.Tx, po *model.PurchaseOrder) error {
+	var active bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT name, active
+		FROM suppliers
+		WHERE id = $1
+	`, po.SupplierID).Scan(&po.SupplierName, &active)
+
+	if err == sql.ErrNoRows {
+		return ErrSupplierNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrSupplierInactive
+	}
+
+	po.OutletID, err = resolveOutlet(ctx, tx, po.OutletID)
+	return err
+}
+
+// produk / varian dicek lewat lockStock (produk bervarian wajib variant_id)
+func insertPurchaseOrderItems(ctx context.Context, tx *sql.Tx, po *model.PurchaseOrder) error {
+	po.TotalCost = 0
+
+	for i := range po.Items {
+		item := &po.Items[i]
+		item.PurchaseOrderID = po.ID
+		item.ReceivedQuantity = 0
+
+		if _, err := lockStock(ctx, tx, item.ProductID, item.VariantID); err != nil {
+			return err
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO purchase_order_items
+				(purchase_order_id, product_id, variant_id, quantity, unit_cost)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`,
+			po.ID,
+			item.ProductID,
+			item.VariantID,
+			item.Quantity,
+			item.UnitCost,
+		).Scan(&item.ID)
+		if err != nil {
+			return err
+		}
+
+		po.TotalCost += item.Quantity * item.UnitCost
+	}
+
+	return nil
+}
+
+// =====================================================
+// WEIGHTED-AVERAGE COST (HPP)
+// - stock_before = stok produk + semua varian (sebelum barang ini masuk)
+// - dibulatkan ke rupiah terdekat
+// =====================================================
+func applyCostChange(
+	ctx context.Context,
+	tx *sql.Tx,
+	purchaseOrderID int,
+	item *model.PurchaseOrderItem,
+	qty int,
+) error {
+
+	var costBefore, stockBefore int
+	err := tx.QueryRowContext(ctx, `
+		SELECT
+			p.harga_pokok,
+			p.stok + COALESCE((
+				SELECT SUM(v.stok)
+				FROM product_variants v
+				WHERE v.product_id = p.id
+			), 0)
+		FROM products p
+		WHERE p.id = $1
+		FOR UPDATE OF p
+	`, item.ProductID).Scan(&costBefore, &stockBefore)
+
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	stockBefore = max(stockBefore, 0)
+	units := stockBefore + qty
+	costAfter := (costBefore*stockBefore + item.UnitCost*qty + units/2) / units
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE products
+		SET harga_pokok = $1
+		WHERE id = $2
+	`, costAfter, item.ProductID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_cost_history
+			(product_id, variant_id, purchase_order_id, quantity, unit_cost,
+			 stock_before, cost_before, cost_after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		item.ProductID,
+		item.VariantID,
+		purchaseOrderID,
+		qty,
+		item.UnitCost,
+		stockBefore,
+		costBefore,
+		costAfter,
+	)
+	return err
+}
+
+// HPP diset manual (PUT /product/{id}): dicatat dengan quantity 0, tanpa purchase order
+func setCostPrice(ctx context.Context, tx *sql.Tx, productID, stock, costBefore, costAfter int) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE products
+		SET harga_pokok = $1
+		WHERE id = $2
+	`, costAfter, productID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_cost_history
+			(product_id, quantity, unit_cost, stock_before, cost_before, cost_after)
+		VALUES ($1, 0, $2, $3, $4, $2)
+	`,
+		productID,
+		costAfter,
+		stock,
+		costBefore,
+	)
+	return err
+}
@@ -1,15 +1,18 @@
 package repository
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
-	"time"
+	"encoding/json"
+	"math"
+	"slices"
 
 	"github.com/jackyansen22/crud-category/internal/model"
 )
 
 type ReportRepository interface {
-	GetReport(ctx context.Context, start, end time.Time) (*model.ReportResponse, error)
+	GetReport(ctx context.Context, f model.ReportFilter) (*model.ReportResponse, error)
 }
 
 type reportRepository struct {
@@ -22,22 +25,25 @@ func NewReportRepository(db *sql.DB) ReportRepository {
 
 func (r *reportRepository) GetReport(
 	ctx context.Context,
-	start, end time.Time,
+	f model.ReportFilter,
 ) (*model.ReportResponse, error) {
 
 	var report model.ReportResponse
 
 	// ===============================
 	// Total revenue & total transaksi
+	// (transaksi void tidak dihitung)
 	// ===============================
+	var grossRevenue int
 	err := r.db.QueryRowContext(ctx, `
 		SELECT
 			COALESCE(SUM(total_amount), 0),
-			COUNT(*)
+			COUNT(*) FILTER (WHERE status <> 'voided')
 		FROM transactions
 		WHERE created_at >= $1 AND created_at < $2
-	`, start, end).Scan(
-		&report.TotalRevenue,
+		  AND ($3::integer IS NULL OR outlet_id = $3)
+	`, f.Start, f.End, f.OutletID).Scan(
+		&grossRevenue,
 		&report.TotalTransaksi,
 	)
 	if err != nil {
@@ -45,32 +51,418 @@ func (r *reportRepository) GetReport(
 	}
 
 	// ===============================
-	// Produk terlaris (qty terbanyak)
+	// Refund & void di periode ini
+	// (dicatat pada tanggal refund, bukan tanggal jual)
 	// ===============================
 	err = r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(rf.total_amount), 0)
+		FROM refunds rf
+		JOIN transactions t ON t.id = rf.transaction_id
+		WHERE rf.created_at >= $1 AND rf.created_at < $2
+		  AND ($3::integer IS NULL OR t.outlet_id = $3)
+	`, f.Start, f.End, f.OutletID).Scan(&report.TotalRefund)
+	if err != nil {
+		return nil, err
+	}
+
+	report.TotalRevenue = grossRevenue - report.TotalRefund
+
+	// ===============================
+	// COGS: HPP barang terjual di periode ini
+	// dikurangi HPP barang yang direfund di periode ini
+	// ===============================
+	err = r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE((
+				SELECT SUM(td.quantity * td.unit_cost)
+				FROM transaction_details td
+				JOIN transactions t ON t.id = td.transaction_id
+				WHERE t.created_at >= $1 AND t.created_at < $2
+				  AND ($3::integer IS NULL OR t.outlet_id = $3)
+			), 0)
+			-
+			COALESCE((
+				SELECT SUM(ri.quantity * td.unit_cost)
+				FROM refund_items ri
+				JOIN refunds rf ON rf.id = ri.refund_id
+				JOIN transaction_details td ON td.id = ri.transaction_detail_id
+				JOIN transactions t ON t.id = rf.transaction_id
+				WHERE rf.created_at >= $1 AND rf.created_at < $2
+				  AND ($3::integer IS NULL OR t.outlet_id = $3)
+			), 0)
+	`, f.Start, f.End, f.OutletID).Scan(&report.COGS)
+	if err != nil {
+		return nil, err
+	}
+
+	report.GrossProfit = report.TotalRevenue - report.COGS
+	report.Margin = margin(report.TotalRevenue, report.GrossProfit)
+
+	// ===============================
+	// Breakdown pembayaran per metode
+	// (amount sudah dikurangi kembalian)
+	// ===============================
+	payments, err := r.paymentSummary(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	report.Payments = payments
+
+	// ===============================
+	// Penjualan per kategori
+	// ===============================
+	categories, err := r.categorySales(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	report.Kategori = categories
+
+	// ===============================
+	// Penjualan per produk (+ breakdown varian)
+	// ===============================
+	products, err := r.productSales(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	report.Produk = products
+
+	// ===============================
+	// Top-N produk, basket size & item per transaksi
+	// (dihitung dari penjualan per produk di atas)
+	// ===============================
+	report.TopByQty, report.TopByRevenue = topProducts(products, f.TopN)
+	if len(report.TopByQty) > 0 {
+		report.ProdukTerlaris = model.BestSeller{
+			Nama:       report.TopByQty[0].Nama,
+			QtyTerjual: report.TopByQty[0].QtyTerjual,
+		}
+	}
+
+	var qty int
+	for _, p := range products {
+		qty += p.QtyTerjual
+	}
+	report.RataRataBasket = ratio(report.TotalRevenue, report.TotalTransaksi)
+	report.ItemPerTrx = ratio(qty, report.TotalTransaksi)
+
+	// ===============================
+	// Time series per hari (zero-filled)
+	// ===============================
+	daily, err := r.dailySales(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	report.Harian = daily
+
+	return &report, nil
+}
+
+// ranking top-N by qty & by revenue, tie-break deterministik
+func topProducts(products []model.ProductSales, n int) (byQty, byRevenue []model.ProductRank) {
+	ranks := make([]model.ProductRank, len(products))
+	for i, p := range products {
+		ranks[i] = model.ProductRank{
+			ProductID:  p.ProductID,
+			Nama:       p.Nama,
+			QtyTerjual: p.QtyTerjual,
+			Revenue:    p.Revenue,
+		}
+	}
+
+	byQty = slices.Clone(ranks)
+	slices.SortFunc(byQty, func(a, b model.ProductRank) int {
+		return cmp.Or(
+			cmp.Compare(b.QtyTerjual, a.QtyTerjual),
+			cmp.Compare(b.Revenue, a.Revenue),
+			cmp.Compare(a.ProductID, b.ProductID),
+		)
+	})
+
+	byRevenue = ranks
+	slices.SortFunc(byRevenue, func(a, b model.ProductRank) int {
+		return cmp.Or(
+			cmp.Compare(b.Revenue, a.Revenue),
+			cmp.Compare(b.QtyTerjual, a.QtyTerjual),
+			cmp.Compare(a.ProductID, b.ProductID),
+		)
+	})
+
+	if n > 0 && len(ranks) > n {
+		byQty, byRevenue = byQty[:n], byRevenue[:n]
+	}
+	return byQty, byRevenue
+}
+
+// =====================================================
+// Penjualan per hari
+// - hari = tanggal bisnis di f.Location, digeser f.DayCutoff
+// - revenue dikurangi refund pada tanggal refund (sama dengan total)
+// - qty net refund dicatat pada tanggal jual (sama dengan per produk)
+// =====================================================
+func (r *reportRepository) dailySales(
+	ctx context.Context,
+	f model.ReportFilter,
+) ([]model.DailySales, error) {
+
+	// f.Start = awal hari bisnis, jadi tanggalnya = tanggal bisnis
+	days := make([]model.DailySales, 0)
+	index := make(map[string]int)
+	for d := f.Start.In(f.Location); d.Before(f.End); d = d.AddDate(0, 0, 1) {
+		index[d.Format("2006-01-02")] = len(days)
+		days = append(days, model.DailySales{Tanggal: d.Format("2006-01-02")})
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		WITH sales AS (
+			SELECT
+				TO_CHAR((t.created_at - $5 * INTERVAL '1 second') AT TIME ZONE $4, 'YYYY-MM-DD') AS day,
+				SUM(t.total_amount) AS gross,
+				COUNT(*) FILTER (WHERE t.status <> 'voided') AS trx
+			FROM transactions t
+			WHERE t.created_at >= $1 AND t.created_at < $2
+			  AND ($3::integer IS NULL OR t.outlet_id = $3)
+			GROUP BY 1
+		), refunded AS (
+			SELECT
+				TO_CHAR((rf.created_at - $5 * INTERVAL '1 second') AT TIME ZONE $4, 'YYYY-MM-DD') AS day,
+				SUM(rf.total_amount) AS amount
+			FROM refunds rf
+			JOIN transactions t ON t.id = rf.transaction_id
+			WHERE rf.created_at >= $1 AND rf.created_at < $2
+			  AND ($3::integer IS NULL OR t.outlet_id = $3)
+			GROUP BY 1
+		), qty AS (
+			SELECT
+				TO_CHAR((t.created_at - $5 * INTERVAL '1 second') AT TIME ZONE $4, 'YYYY-MM-DD') AS day,
+				SUM(td.quantity - COALESCE(ri.qty, 0)) AS qty
+			FROM transaction_details td
+			JOIN transactions t ON t.id = td.transaction_id
+			LEFT JOIN (
+				SELECT transaction_detail_id, SUM(quantity) AS qty
+				FROM refund_items
+				GROUP BY transaction_detail_id
+			) ri ON ri.transaction_detail_id = td.id
+			WHERE t.created_at >= $1 AND t.created_at < $2
+			  AND ($3::integer IS NULL OR t.outlet_id = $3)
+			GROUP BY 1
+		)
 		SELECT
-			p.nama,
-			SUM(td.quantity) AS qty_terjual
+			d.day,
+			COALESCE(s.gross, 0),
+			COALESCE(rf.amount, 0),
+			COALESCE(s.trx, 0),
+			COALESCE(q.qty, 0)
+		FROM (
+			SELECT day FROM sales
+			UNION SELECT day FROM refunded
+			UNION SELECT day FROM qty
+		) d
+		LEFT JOIN sales s ON s.day = d.day
+		LEFT JOIN refunded rf ON rf.day = d.day
+		LEFT JOIN qty q ON q.day = d.day
+	`, f.Start, f.End, f.OutletID, f.Location.String(), int(f.DayCutoff.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			day                     string
+			gross, refund, trx, qty int
+		)
+		if err := rows.Scan(&day, &gross, &refund, &trx, &qty); err != nil {
+			return nil, err
+		}
+		i, ok := index[day]
+		if !ok {
+			continue
+		}
+
+		d := &days[i]
+		d.Revenue = gross - refund
+		d.TotalRefund = refund
+		d.TotalTransaksi = trx
+		d.QtyTerjual = qty
+	}
+
+	return days, rows.Err()
+}
+
+func (r *reportRepository) productSales(
+	ctx context.Context,
+	f model.ReportFilter,
+) ([]model.ProductSales, error) {
+
+	// satu baris per (produk, varian); baris tanpa varian = variant_id NULL
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			td.product_id,
+			(ARRAY_AGG(td.product_name ORDER BY td.id DESC))[1],
+			td.variant_id,
+			(ARRAY_AGG(td.variant_attributes ORDER BY td.id DESC))[1],
+			SUM(td.quantity - COALESCE(rf.qty, 0)),
+			SUM(td.subtotal - COALESCE(rf.amount, 0)),
+			SUM((td.quantity - COALESCE(rf.qty, 0)) * td.unit_cost)
 		FROM transaction_details td
-		JOIN products p ON p.id = td.product_id
 		JOIN transactions t ON t.id = td.transaction_id
+		LEFT JOIN (
+			SELECT transaction_detail_id, SUM(quantity) AS qty, SUM(amount) AS amount
+			FROM refund_items
+			GROUP BY transaction_detail_id
+		) rf ON rf.transaction_detail_id = td.id
 		WHERE t.created_at >= $1 AND t.created_at < $2
-		GROUP BY p.nama
-		ORDER BY qty_terjual DESC
-		LIMIT 1
-	`, start, end).Scan(
-		&report.ProdukTerlaris.Nama,
-		&report.ProdukTerlaris.QtyTerjual,
-	)
+		  AND ($3::integer IS NULL OR t.outlet_id = $3)
+		GROUP BY td.product_id, td.variant_id
+		HAVING SUM(td.quantity - COALESCE(rf.qty, 0)) > 0
+		ORDER BY td.product_id, td.variant_id NULLS FIRST
+	`, f.Start, f.End, f.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	// kalau tidak ada transaksi sama sekali
-	if err == sql.ErrNoRows {
-		report.ProdukTerlaris = model.BestSeller{}
-		return &report, nil
+	products := make([]model.ProductSales, 0)
+	for rows.Next() {
+		var (
+			productID  int
+			nama       string
+			variantID  sql.NullInt64
+			attributes []byte
+			qty        int
+			revenue    int
+			cogs       int
+		)
+		if err := rows.Scan(&productID, &nama, &variantID, &attributes, &qty, &revenue, &cogs); err != nil {
+			return nil, err
+		}
+
+		if n := len(products); n == 0 || products[n-1].ProductID != productID {
+			products = append(products, model.ProductSales{ProductID: productID, Nama: nama})
+		}
+		p := &products[len(products)-1]
+		p.QtyTerjual += qty
+		p.Revenue += revenue
+		p.COGS += cogs
+		p.GrossProfit = p.Revenue - p.COGS
+		p.Margin = margin(p.Revenue, p.GrossProfit)
+
+		if !variantID.Valid {
+			continue
+		}
+
+		v := model.VariantSales{
+			VariantID:   int(variantID.Int64),
+			QtyTerjual:  qty,
+			Revenue:     revenue,
+			COGS:        cogs,
+			GrossProfit: revenue - cogs,
+			Margin:      margin(revenue, revenue-cogs),
+		}
+		if err := json.Unmarshal(attributes, &v.Attributes); err != nil {
+			return nil, err
+		}
+		p.Variants = append(p.Variants, v)
 	}
+
+	return products, rows.Err()
+}
+
+func (r *reportRepository) categorySales(
+	ctx context.Context,
+	f model.ReportFilter,
+) ([]model.CategorySales, error) {
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			td.category_id,
+			(ARRAY_AGG(td.category_name ORDER BY td.id DESC))[1],
+			SUM(td.quantity - COALESCE(rf.qty, 0)),
+			SUM(td.subtotal - COALESCE(rf.amount, 0)),
+			SUM((td.quantity - COALESCE(rf.qty, 0)) * td.unit_cost)
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		LEFT JOIN (
+			SELECT transaction_detail_id, SUM(quantity) AS qty, SUM(amount) AS amount
+			FROM refund_items
+			GROUP BY transaction_detail_id
+		) rf ON rf.transaction_detail_id = td.id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		  AND ($3::integer IS NULL OR t.outlet_id = $3)
+		GROUP BY td.category_id
+		HAVING SUM(td.quantity - COALESCE(rf.qty, 0)) > 0
+		ORDER BY 4 DESC, td.category_id NULLS LAST
+	`, f.Start, f.End, f.OutletID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return &report, nil
+	categories := make([]model.CategorySales, 0)
+	for rows.Next() {
+		var (
+			c          model.CategorySales
+			categoryID sql.NullInt64
+		)
+		if err := rows.Scan(&categoryID, &c.Nama, &c.QtyTerjual, &c.Revenue, &c.COGS); err != nil {
+			return nil, err
+		}
+		if categoryID.Valid {
+			id := int(categoryID.Int64)
+			c.CategoryID = &id
+		}
+		c.GrossProfit = c.Revenue - c.COGS
+		c.Margin = margin(c.Revenue, c.GrossProfit)
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// margin dalam persen dari revenue, dibulatkan 2 desimal
+func margin(revenue, grossProfit int) float64 {
+	return ratio(grossProfit*100, revenue)
+}
+
+// a / b dibulatkan 2 desimal (0 kalau b = 0)
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return math.Round(float64(a)*100/float64(b)) / 100
+}
+
+func (r *reportRepository) paymentSummary(
+	ctx context.Context,
+	f model.ReportFilter,
+) ([]model.PaymentSummary, error) {
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			tp.method,
+			SUM(tp.amount),
+			COUNT(DISTINCT tp.transaction_id)
+		FROM transaction_payments tp
+		JOIN transactions t ON t.id = tp.transaction_id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		  AND ($3::integer IS NULL OR t.outlet_id = $3)
+		  AND t.status <> 'voided'
+		GROUP BY tp.method
+		ORDER BY tp.method
+	`, f.Start, f.End, f.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]model.PaymentSummary, 0)
+	for rows.Next() {
+		var p model.PaymentSummary
+		if err := rows.Scan(&p.Method, &p.Total, &p.JumlahTransaksi); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
 }
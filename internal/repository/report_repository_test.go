@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+func TestTopProducts(t *testing.T) {
+	products := []model.ProductSales{
+		{ProductID: 4, Nama: "D", QtyTerjual: 5, Revenue: 50000},
+		{ProductID: 2, Nama: "B", QtyTerjual: 10, Revenue: 20000},
+		{ProductID: 3, Nama: "C", QtyTerjual: 10, Revenue: 20000}, // seri penuh dengan B
+		{ProductID: 1, Nama: "A", QtyTerjual: 10, Revenue: 30000}, // seri qty, revenue lebih besar
+		{ProductID: 5, Nama: "E", QtyTerjual: 2, Revenue: 50000},  // seri revenue dengan D
+	}
+
+	ids := func(ranks []model.ProductRank) []int {
+		out := make([]int, len(ranks))
+		for i, r := range ranks {
+			out[i] = r.ProductID
+		}
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		n           int
+		wantQty     []int
+		wantRevenue []int
+	}{
+		{
+			name:        "n = 0 tanpa batas",
+			n:           0,
+			wantQty:     []int{1, 2, 3, 4, 5},
+			wantRevenue: []int{4, 5, 1, 2, 3},
+		},
+		{
+			name:        "top 2",
+			n:           2,
+			wantQty:     []int{1, 2},
+			wantRevenue: []int{4, 5},
+		},
+		{
+			name:        "n > jumlah produk",
+			n:           50,
+			wantQty:     []int{1, 2, 3, 4, 5},
+			wantRevenue: []int{4, 5, 1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// urutan input tidak boleh mempengaruhi hasil
+			for _, in := range [][]model.ProductSales{products, reversed(products)} {
+				byQty, byRevenue := topProducts(in, tt.n)
+
+				if got := ids(byQty); !reflect.DeepEqual(got, tt.wantQty) {
+					t.Errorf("byQty = %v, want %v", got, tt.wantQty)
+				}
+				if got := ids(byRevenue); !reflect.DeepEqual(got, tt.wantRevenue) {
+					t.Errorf("byRevenue = %v, want %v", got, tt.wantRevenue)
+				}
+			}
+		})
+	}
+
+	byQty, byRevenue := topProducts(nil, 5)
+	if len(byQty) != 0 || len(byRevenue) != 0 {
+		t.Errorf("empty input = %v, %v", byQty, byRevenue)
+	}
+}
+
+func reversed[T any](s []T) []T {
+	out := make([]T, len(s))
+	for i, v := range s {
+		out[len(s)-1-i] = v
+	}
+	return out
+}
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		a, b int
+		want float64
+	}{
+		{0, 0, 0},
+		{5, 0, 0},
+		{1, 3, 0.33},
+		{2, 3, 0.67},
+		{10, 4, 2.5},
+		{-1, 3, -0.33},
+	}
+
+	for _, tt := range tests {
+		if got := ratio(tt.a, tt.b); got != tt.want {
+			t.Errorf("ratio(%d, %d) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	// margin dalam persen
+	if got := margin(30000, 7500); got != 25 {
+		t.Errorf("margin = %v, want 25", got)
+	}
+}
@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/auth"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+var (
+	ErrProductNotFound = apperror.NotFound("PRODUCT_NOT_FOUND", "product not found")
+	ErrNegativeStock   = apperror.Unprocessable("NEGATIVE_STOCK", "stock cannot go below zero")
+	ErrVariantNotFound = apperror.NotFound("VARIANT_NOT_FOUND", "product variant not found")
+	ErrVariantRequired = apperror.Validation("VARIANT_REQUIRED", "product has variants, variant_id is required")
+)
+
+type StockRepository interface {
+	Adjust(ctx context.Context, productID int, req model.StockAdjustmentRequest) (*model.StockMovement, error)
+	FindByProduct(ctx context.Context, productID int) ([]model.StockMovement, error)
+	CheckConsistency(ctx context.Context) ([]model.StockDrift, error)
+	Rebuild(ctx context.Context) ([]model.StockDrift, error)
+}
+
+type stockRepository struct {
+	db *sql.DB
+}
+
+func NewStockRepository(db *sql.DB) StockRepository {
+	return &stockRepository{db: db}
+}
+
+// =====================================================
+// MANUAL MOVEMENT (adjustment / receiving / stocktake)
+// =====================================================
+func (r *stockRepository) Adjust(
+	ctx context.Context,
+	productID int,
+	req model.StockAdjustmentRequest,
+) (*model.StockMovement, error) {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	outletID, err := resolveOutlet(ctx, tx, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🔒 lock product / variant row, lalu stok di outlet
+	if _, err := lockStock(ctx, tx, productID, req.VariantID); err != nil {
+		return nil, err
+	}
+	stock, err := lockOutletStock(ctx, tx, outletID, productID, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := req.Quantity
+	if req.Type == model.StockMovementStocktake {
+		delta = req.Quantity - stock
+	}
+
+	m := &model.StockMovement{
+		ProductID:   productID,
+		VariantID:   req.VariantID,
+		OutletID:    outletID,
+		Type:        req.Type,
+		Quantity:    delta,
+		Reason:      req.Reason,
+		ReferenceID: req.ReferenceID,
+	}
+	if err := applyStockChange(ctx, tx, m); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// =====================================================
+// STOCK HISTORY (terbaru di atas)
+// =====================================================
+func (r *stockRepository) FindByProduct(
+	ctx context.Context,
+	productID int,
+) ([]model.StockMovement, error) {
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)
+	`, productID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			id,
+			product_id,
+			variant_id,
+			outlet_id,
+			type,
+			quantity,
+			stock_after,
+			reason,
+			reference_id,
+			user_id,
+			created_at
+		FROM stock_movements
+		WHERE product_id = $1
+		ORDER BY id DESC
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]model.StockMovement, 0)
+	for rows.Next() {
+		var m model.StockMovement
+		if err := rows.Scan(
+			&m.ID,
+			&m.ProductID,
+			&m.VariantID,
+			&m.OutletID,
+			&m.Type,
+			&m.Quantity,
+			&m.StockAfter,
+			&m.Reason,
+			&m.ReferenceID,
+			&m.UserID,
+			&m.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, rows.Err()
+}
+
+// =====================================================
+// CONSISTENCY CHECK: products.stok / product_variants.stok vs SUM(ledger)
+// =====================================================
+const stockDriftQuery = `
+	WITH ledger AS (
+		SELECT product_id, variant_id, SUM(quantity) AS stok
+		FROM stock_movements
+		GROUP BY product_id, variant_id
+	)
+	SELECT p.id, NULL::integer, p.nama, p.stok, COALESCE(l.stok, 0)
+	FROM products p
+	LEFT JOIN ledger l ON l.product_id = p.id AND l.variant_id IS NULL
+	WHERE p.stok <> COALESCE(l.stok, 0)
+
+	UNION ALL
+
+	SELECT v.product_id, v.id, p.nama, v.stok, COALESCE(l.stok, 0)
+	FROM product_variants v
+	JOIN products p ON p.id = v.product_id
+	LEFT JOIN ledger l ON l.variant_id = v.id
+	WHERE v.stok <> COALESCE(l.stok, 0)
+
+	ORDER BY 1, 2 NULLS FIRST
+`
+
+func (r *stockRepository) CheckConsistency(ctx context.Context) ([]model.StockDrift, error) {
+	rows, err := r.db.QueryContext(ctx, stockDriftQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStockDrifts(rows)
+}
+
+// =====================================================
+// REBUILD: set products.stok = SUM(ledger)
+// return produk yang tadinya drift
+// =====================================================
+func (r *stockRepository) Rebuild(ctx context.Context) ([]model.StockDrift, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, stockDriftQuery)
+	if err != nil {
+		return nil, err
+	}
+	drifts, err := scanStockDrifts(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// lock dulu, baru hitung ulang ledger (bisa berubah sejak query drift)
+	for i, d := range drifts {
+		lock := `SELECT 1 FROM products WHERE id = $1 FOR UPDATE`
+		target := d.ProductID
+		if d.VariantID != nil {
+			lock = `SELECT 1 FROM product_variants WHERE id = $1 FOR UPDATE`
+			target = *d.VariantID
+		}
+		if _, err := tx.ExecContext(ctx, lock, target); err != nil {
+			return nil, err
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE(SUM(quantity), 0)
+			FROM stock_movements
+			WHERE product_id = $1 AND variant_id IS NOT DISTINCT FROM $2
+		`, d.ProductID, d.VariantID).Scan(&drifts[i].LedgerStok)
+		if err != nil {
+			return nil, err
+		}
+		drifts[i].Drift = d.Stok - drifts[i].LedgerStok
+
+		if d.VariantID != nil {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE product_variants
+				SET stok = $1
+				WHERE id = $2
+			`, drifts[i].LedgerStok, *d.VariantID)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE products
+				SET stok = $1
+				WHERE id = $2
+			`, drifts[i].LedgerStok, d.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return drifts, nil
+}
+
+func scanStockDrifts(rows *sql.Rows) ([]model.StockDrift, error) {
+	drifts := make([]model.StockDrift, 0)
+	for rows.Next() {
+		var d model.StockDrift
+		if err := rows.Scan(&d.ProductID, &d.VariantID, &d.Nama, &d.Stok, &d.LedgerStok); err != nil {
+			return nil, err
+		}
+		d.Drift = d.Stok - d.LedgerStok
+		drifts = append(drifts, d)
+	}
+
+	return drifts, rows.Err()
+}
+
+// =====================================================
+// LEDGER HELPERS (dipakai repository lain dalam sql.Tx)
+// - lockStock          : lock row produk / varian, return stok total
+// - lockOutletStock    : lock stok item di satu outlet (0 kalau belum ada)
+// - applyStockChange   : update stok total + stok outlet, catat movement
+// - insertStockMovement: catat movement saja (stock_after sudah diisi)
+// =====================================================
+
+// produk yang punya varian aktif wajib pakai variantID
+func lockStock(ctx context.Context, tx *sql.Tx, productID int, variantID *int) (int, error) {
+	var stock int
+
+	if variantID != nil {
+		err := tx.QueryRowContext(ctx, `
+			SELECT v.stok
+			FROM product_variants v
+			JOIN products p ON p.id = v.product_id
+			WHERE v.id = $1 AND v.product_id = $2
+			  AND v.deleted_at IS NULL AND p.deleted_at IS NULL
+			FOR UPDATE OF v
+		`, *variantID, productID).Scan(&stock)
+		if err == sql.ErrNoRows {
+			return 0, ErrVariantNotFound
+		}
+		return stock, err
+	}
+
+	var hasVariants bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT p.stok, EXISTS (
+			SELECT 1 FROM product_variants v
+			WHERE v.product_id = p.id AND v.deleted_at IS NULL
+		)
+		FROM products p
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+		FOR UPDATE OF p
+	`, productID).Scan(&stock, &hasVariants)
+	if err == sql.ErrNoRows {
+		return 0, ErrProductNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if hasVariants {
+		return 0, ErrVariantRequired
+	}
+
+	return stock, nil
+}
+
+// row outlet_stock belum ada = stok 0 di outlet itu
+// (row product / variant harus sudah di-lock, jadi insert paralel tidak mungkin)
+func lockOutletStock(ctx context.Context, tx *sql.Tx, outletID, productID int, variantID *int) (int, error) {
+	var stock int
+	err := tx.QueryRowContext(ctx, `
+		SELECT stok
+		FROM outlet_stock
+		WHERE outlet_id = $1 AND product_id = $2
+		  AND COALESCE(variant_id, 0) = COALESCE($3::integer, 0)
+		FOR UPDATE
+	`, outletID, productID, variantID).Scan(&stock)
+
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return stock, err
+}
+
+// OutletID 0 = outlet default
+func applyStockChange(ctx context.Context, tx *sql.Tx, m *model.StockMovement) error {
+	if m.OutletID == 0 {
+		outletID, err := resolveOutlet(ctx, tx, 0)
+		if err != nil {
+			return err
+		}
+		m.OutletID = outletID
+	}
+
+	var (
+		total int
+		err   error
+	)
+	if m.VariantID != nil {
+		err = tx.QueryRowContext(ctx, `
+			UPDATE product_variants
+			SET stok = stok + $1
+			WHERE id = $2
+			RETURNING stok
+		`, m.Quantity, *m.VariantID).Scan(&total)
+	} else {
+		err = tx.QueryRowContext(ctx, `
+			UPDATE products
+			SET stok = stok + $1
+			WHERE id = $2
+			RETURNING stok
+		`, m.Quantity, m.ProductID).Scan(&total)
+	}
+
+	if err == sql.ErrNoRows && m.VariantID != nil {
+		return ErrVariantNotFound
+	}
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if total < 0 {
+		return ErrNegativeStock
+	}
+
+	// stok di outlet movement
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO outlet_stock (outlet_id, product_id, variant_id, stok)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (outlet_id, product_id, (COALESCE(variant_id, 0)))
+		DO UPDATE SET stok = outlet_stock.stok + EXCLUDED.stok
+		RETURNING stok
+	`, m.OutletID, m.ProductID, m.VariantID, m.Quantity).Scan(&m.StockAfter)
+	if err != nil {
+		return err
+	}
+	if m.StockAfter < 0 {
+		return ErrNegativeStock
+	}
+
+	return insertStockMovement(ctx, tx, m)
+}
+
+// user_id default dari user yang login (context)
+func insertStockMovement(ctx context.Context, tx *sql.Tx, m *model.StockMovement) error {
+	if m.UserID == nil {
+		m.UserID = auth.UserIDFromContext(ctx)
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO stock_movements
+			(product_id, variant_id, outlet_id, type, quantity, stock_after, reason, reference_id, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`,
+		m.ProductID,
+		m.VariantID,
+		m.OutletID,
+		m.Type,
+		m.Quantity,
+		m.StockAfter,
+		m.Reason,
+		m.ReferenceID,
+		m.UserID,
+	).Scan(&m.ID, &m.CreatedAt)
+}
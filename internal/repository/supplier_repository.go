@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+var (
+	ErrSupplierNotFound  = apperror.NotFound("SUPPLIER_NOT_FOUND", "supplier not found")
+	ErrSupplierInactive  = apperror.Unprocessable("SUPPLIER_INACTIVE", "supplier is inactive")
+	ErrSupplierNameTaken = apperror.Conflict("SUPPLIER_NAME_TAKEN", "supplier name already used")
+)
+
+type SupplierRepository interface {
+	FindAll(ctx context.Context) ([]model.Supplier, error)
+	FindByID(ctx context.Context, id int) (*model.Supplier, error)
+	Create(ctx context.Context, s *model.Supplier) error
+	Update(ctx context.Context, s *model.Supplier) error
+}
+
+type supplierRepository struct {
+	db *sql.DB
+}
+
+func NewSupplierRepository(db *sql.DB) SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
+const supplierColumns = `
+	id, name, phone, email, address, active, created_at, updated_at
+`
+
+func (r *supplierRepository) FindAll(ctx context.Context) ([]model.Supplier, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+supplierColumns+`
+		FROM suppliers
+		ORDER BY name, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := make([]model.Supplier, 0)
+	for rows.Next() {
+		s, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, *s)
+	}
+
+	return suppliers, rows.Err()
+}
+
+func (r *supplierRepository) FindByID(ctx context.Context, id int) (*model.Supplier, error) {
+	s, err := scanSupplier(r.db.QueryRowContext(ctx, `
+		SELECT `+supplierColumns+`
+		FROM suppliers
+		WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, ErrSupplierNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r *supplierRepository) Create(ctx context.Context, s *model.Supplier) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO suppliers (name, phone, email, address, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`,
+		s.Name,
+		s.Phone,
+		s.Email,
+		s.Address,
+		s.Active,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+
+	if isUniqueViolation(err) {
+		return ErrSupplierNameTaken
+	}
+	return err
+}
+
+func (r *supplierRepository) Update(ctx context.Context, s *model.Supplier) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE suppliers
+		SET name = $1,
+		    phone = $2,
+		    email = $3,
+		    address = $4,
+		    active = $5
+		WHERE id = $6
+		RETURNING created_at, updated_at
+	`,
+		s.Name,
+		s.Phone,
+		s.Email,
+		s.Address,
+		s.Active,
+		s.ID,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrSupplierNotFound
+	}
+	if isUniqueViolation(err) {
+		return ErrSupplierNameTaken
+	}
+	return err
+}
+
+func scanSupplier(row rowScanner) (*model.Supplier, error) {
+	var s model.Supplier
+	if err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&s.Phone,
+		&s.Email,
+		&s.Address,
+		&s.Active,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
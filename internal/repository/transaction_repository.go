@@ -3,21 +3,47 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/auth"
 	"github.com/jackyansen22/crud-category/internal/model"
 )
 
+var (
+	ErrTransactionNotFound = apperror.NotFound("TRANSACTION_NOT_FOUND", "transaction not found")
+	ErrTransactionVoided   = apperror.Conflict("TRANSACTION_VOIDED", "transaction already voided")
+	ErrTransactionRefunded = apperror.Conflict("TRANSACTION_REFUNDED", "transaction already fully refunded")
+	ErrVoidAfterRefund     = apperror.Conflict("VOID_AFTER_REFUND", "transaction with refunds cannot be voided")
+	ErrRefundExceedsSold   = apperror.Unprocessable("REFUND_EXCEEDS_SOLD", "refund quantity exceeds quantity sold")
+	ErrRefundItemNotFound  = apperror.Validation("REFUND_ITEM_NOT_FOUND", "refund item not found in transaction")
+	ErrInvalidRefundQty    = apperror.Validation("INVALID_REFUND_QUANTITY", "refund quantity must be greater than zero")
+
+	ErrInsufficientStock   = apperror.Unprocessable("INSUFFICIENT_STOCK", "stock not enough")
+	ErrInvalidTotal        = apperror.Validation("INVALID_TOTAL", "total amount must be greater than zero")
+	ErrPaymentInsufficient = apperror.Unprocessable("PAYMENT_INSUFFICIENT", "payments do not cover total amount")
+	ErrNonCashOverpayment  = apperror.Validation("NON_CASH_OVERPAYMENT", "non-cash payments cannot exceed the amount due")
+
+	ErrIdempotencyKeyExists = apperror.Conflict("IDEMPOTENCY_KEY_IN_USE", "idempotency key already used")
+)
+
 type TransactionRepository interface {
 	CreateTransaction(
 		ctx context.Context,
-		items []model.CheckoutItem,
+		req *model.CheckoutRequest,
 	) (*model.Transaction, error)
 
-	FindAll(ctx context.Context) ([]model.Transaction, error)
+	FindAll(ctx context.Context, page model.PageRequest) (*model.Page[model.Transaction], error)
 	FindByID(ctx context.Context, id int) (*model.Transaction, error)
+	ExportLines(ctx context.Context, f model.ReportFilter, fn func(model.TransactionExportLine) error) error
+
+	FindIdempotencyKey(ctx context.Context, key string) (*model.IdempotencyKey, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+
+	Refund(ctx context.Context, transactionID int, req model.RefundRequest) (*model.Refund, error)
+	Void(ctx context.Context, transactionID int, reason string) (*model.Refund, error)
 }
 
 type transactionRepository struct {
@@ -30,7 +56,7 @@ func NewTransactionRepository(db *sql.DB) TransactionRepository {
 
 func (r *transactionRepository) CreateTransaction(
 	ctx context.Context,
-	items []model.CheckoutItem,
+	req *model.CheckoutRequest,
 ) (*model.Transaction, error) {
 
 	tx, err := r.db.BeginTx(ctx, nil)
@@ -39,64 +65,89 @@ func (r *transactionRepository) CreateTransaction(
 	}
 	defer tx.Rollback()
 
+	// ==========================
+	// IDEMPOTENCY KEY
+	// - key expired boleh dipakai ulang
+	// - INSERT menunggu checkout paralel dengan key sama selesai
+	// ==========================
+	if req.Idempotency != nil {
+		if err := reserveIdempotencyKey(ctx, tx, req.Idempotency); err != nil {
+			return nil, err
+		}
+	}
+
+	// ==========================
+	// OUTLET (0 = outlet default)
+	// ==========================
+	outletID, err := resolveOutlet(ctx, tx, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+
 	totalAmount := 0
 	details := make([]model.TransactionDetail, 0)
 
+	// qty per product/varian yang sudah diambil item sebelumnya (bisa muncul 2x)
+	reserved := make(map[stockKey]int)
+
 	// ==========================
 	// LOOP ITEMS
 	// ==========================
-	for _, item := range items {
-		var (
-			productName  string
-			productPrice int
-			stock        int
-		)
+	for _, item := range req.Items {
+		if err := resolveCheckoutItem(ctx, tx, &item); err != nil {
+			return nil, err
+		}
 
-		// 🔒 lock product row
-		err := tx.QueryRowContext(ctx, `
-			SELECT nama, harga, stok
-			FROM products
-			WHERE id = $1
-			FOR UPDATE
-		`, item.ProductID).Scan(&productName, &productPrice, &stock)
+		d := model.TransactionDetail{ProductID: item.ProductID, Quantity: item.Quantity}
 
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product id %d not found", item.ProductID)
-		}
-		if err != nil {
+		// 🔒 lock product / variant row (+ snapshot nama, harga, category)
+		if err := lockCheckoutItem(ctx, tx, item, &d); err != nil {
 			return nil, err
 		}
 
-		if stock < item.Quantity {
-			return nil, fmt.Errorf(
-				"stock not enough for product %d (available %d)",
-				item.ProductID, stock,
-			)
+		// 🔒 stok hanya dari outlet checkout
+		stock, err := lockOutletStock(ctx, tx, outletID, item.ProductID, d.VariantID)
+		if err != nil {
+			return nil, err
 		}
 
-		subtotal := productPrice * item.Quantity
-		totalAmount += subtotal
+		key := stockKey{productID: item.ProductID, variantID: item.VariantID}
+		if available := stock - reserved[key]; available < item.Quantity {
+			details := map[string]int{
+				"product_id": item.ProductID,
+				"outlet_id":  outletID,
+				"available":  available,
+				"requested":  item.Quantity,
+			}
+			if item.VariantID != 0 {
+				details["variant_id"] = item.VariantID
+			}
 
-		// update stock
-		_, err = tx.ExecContext(ctx, `
-			UPDATE products
-			SET stok = stok - $1
-			WHERE id = $2
-		`, item.Quantity, item.ProductID)
-		if err != nil {
-			return nil, err
+			return nil, ErrInsufficientStock.
+				WithMessage(fmt.Sprintf(
+					"stock not enough for product %d (available %d)",
+					item.ProductID, available,
+				)).
+				WithDetails(details)
 		}
+		reserved[key] += item.Quantity
 
-		details = append(details, model.TransactionDetail{
-			ProductID:   item.ProductID,
-			ProductName: productName,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
-		})
+		d.Subtotal = d.UnitPrice * item.Quantity
+		totalAmount += d.Subtotal
+
+		details = append(details, d)
 	}
 
 	if totalAmount <= 0 {
-		return nil, errors.New("total amount must be greater than zero")
+		return nil, ErrInvalidTotal
+	}
+
+	// ==========================
+	// PAYMENTS (tender & kembalian)
+	// ==========================
+	payments, paidAmount, changeAmount, err := allocatePayments(totalAmount, req.Payments)
+	if err != nil {
+		return nil, err
 	}
 
 	// ==========================
@@ -105,33 +156,70 @@ func (r *transactionRepository) CreateTransaction(
 	var (
 		transactionID int
 		createdAt     time.Time
+		userID        = auth.UserIDFromContext(ctx)
 	)
 
 	err = tx.QueryRowContext(ctx, `
-		INSERT INTO transactions (total_amount)
-		VALUES ($1)
+		INSERT INTO transactions (total_amount, paid_amount, change_amount, status, outlet_id, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at
-	`, totalAmount).Scan(&transactionID, &createdAt)
+	`,
+		totalAmount,
+		paidAmount,
+		changeAmount,
+		model.TransactionStatusCompleted,
+		outletID,
+		userID,
+	).Scan(&transactionID, &createdAt)
 	if err != nil {
 		return nil, err
 	}
 
 	// ==========================
-	// INSERT DETAILS
+	// INSERT DETAILS + KURANGI STOK (ledger: sale)
 	// ==========================
 	for i := range details {
 		details[i].TransactionID = transactionID
 
+		err = applyStockChange(ctx, tx, &model.StockMovement{
+			ProductID:   details[i].ProductID,
+			VariantID:   details[i].VariantID,
+			OutletID:    outletID,
+			Type:        model.StockMovementSale,
+			Quantity:    -details[i].Quantity,
+			Reason:      "checkout",
+			ReferenceID: &transactionID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var attributes []byte
+		if details[i].VariantID != nil {
+			if attributes, err = json.Marshal(details[i].VariantAttributes); err != nil {
+				return nil, err
+			}
+		}
+
 		err = tx.QueryRowContext(ctx, `
 			INSERT INTO transaction_details
-				(transaction_id, product_id, quantity, subtotal)
-			VALUES ($1, $2, $3, $4)
+				(transaction_id, product_id, quantity, subtotal,
+				 unit_price, unit_cost, product_name, category_id, category_name,
+				 variant_id, variant_attributes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 			RETURNING id
 		`,
 			transactionID,
 			details[i].ProductID,
 			details[i].Quantity,
 			details[i].Subtotal,
+			details[i].UnitPrice,
+			details[i].UnitCost,
+			details[i].ProductName,
+			details[i].CategoryID,
+			details[i].CategoryName,
+			details[i].VariantID,
+			attributes,
 		).Scan(&details[i].ID)
 
 		if err != nil {
@@ -139,6 +227,40 @@ func (r *transactionRepository) CreateTransaction(
 		}
 	}
 
+	// ==========================
+	// INSERT PAYMENTS
+	// ==========================
+	for i := range payments {
+		payments[i].TransactionID = transactionID
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO transaction_payments
+				(transaction_id, method, amount, tendered)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`,
+			transactionID,
+			payments[i].Method,
+			payments[i].Amount,
+			payments[i].Tendered,
+		).Scan(&payments[i].ID)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Idempotency != nil {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE idempotency_keys
+			SET transaction_id = $1
+			WHERE key = $2
+		`, transactionID, req.Idempotency.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// ==========================
 	// COMMIT
 	// ==========================
@@ -150,30 +272,347 @@ func (r *transactionRepository) CreateTransaction(
 	// RESPONSE
 	// ==========================
 	return &model.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		CreatedAt:   createdAt,
-		Details:     details,
+		ID:           transactionID,
+		TotalAmount:  totalAmount,
+		PaidAmount:   paidAmount,
+		ChangeAmount: changeAmount,
+		Status:       model.TransactionStatusCompleted,
+		OutletID:     outletID,
+		UserID:       userID,
+		CreatedAt:    createdAt,
+		Details:      details,
+		Payments:     payments,
 	}, nil
 }
 
+// =====================================================
+// RESOLVE ITEM: barcode / sku / variant_id → product_id
+// - sku bisa milik produk atau varian
+// (barcode sudah dinormalisasi ke GTIN-13 di service)
+// =====================================================
+func resolveCheckoutItem(ctx context.Context, tx *sql.Tx, item *model.CheckoutItem) error {
+	var (
+		query string
+		arg   string
+		field string
+	)
+
+	switch {
+	case item.ProductID != 0:
+		return nil
+	case item.VariantID != 0:
+		err := tx.QueryRowContext(ctx, `
+			SELECT product_id
+			FROM product_variants
+			WHERE id = $1 AND deleted_at IS NULL
+		`, item.VariantID).Scan(&item.ProductID)
+		if err == sql.ErrNoRows {
+			return ErrVariantNotFound.
+				WithMessage(fmt.Sprintf("variant id %d not found", item.VariantID)).
+				WithDetails(map[string]int{"variant_id": item.VariantID})
+		}
+		return err
+	case item.Barcode != "":
+		query, arg, field = `
+			SELECT b.product_id
+			FROM product_barcodes b
+			JOIN products p ON p.id = b.product_id
+			WHERE b.code = $1 AND p.deleted_at IS NULL
+		`, item.Barcode, "barcode"
+	default:
+		query, arg, field = `
+			SELECT id, 0
+			FROM products
+			WHERE sku = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT v.product_id, v.id
+			FROM product_variants v
+			JOIN products p ON p.id = v.product_id
+			WHERE v.sku = $1 AND v.deleted_at IS NULL AND p.deleted_at IS NULL
+		`, item.SKU, "sku"
+	}
+
+	var err error
+	if field == "sku" {
+		err = tx.QueryRowContext(ctx, query, arg).Scan(&item.ProductID, &item.VariantID)
+	} else {
+		err = tx.QueryRowContext(ctx, query, arg).Scan(&item.ProductID)
+	}
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound.
+			WithMessage(fmt.Sprintf("product with %s %q not found", field, arg)).
+			WithDetails(map[string]string{field: arg})
+	}
+	return err
+}
+
+type stockKey struct {
+	productID int
+	variantID int
+}
+
+// =====================================================
+// LOCK ITEM
+// - variant_id: lock baris varian, harga dari varian
+// - tanpa variant_id: lock produk, ditolak kalau produk punya varian
+// (stok dicek per outlet, lihat lockOutletStock)
+// =====================================================
+func lockCheckoutItem(
+	ctx context.Context,
+	tx *sql.Tx,
+	item model.CheckoutItem,
+	d *model.TransactionDetail,
+) error {
+
+	if item.VariantID != 0 {
+		var attributes []byte
+		err := tx.QueryRowContext(ctx, `
+			SELECT p.nama, v.harga, p.harga_pokok, v.attributes, p.category_id, COALESCE(c.name, '')
+			FROM product_variants v
+			JOIN products p ON p.id = v.product_id
+			LEFT JOIN categories c ON c.id = p.category_id
+			WHERE v.id = $1 AND v.product_id = $2
+			  AND v.deleted_at IS NULL AND p.deleted_at IS NULL
+			FOR UPDATE OF v
+		`, item.VariantID, item.ProductID).Scan(
+			&d.ProductName, &d.UnitPrice, &d.UnitCost, &attributes, &d.CategoryID, &d.CategoryName,
+		)
+
+		if err == sql.ErrNoRows {
+			return ErrVariantNotFound.
+				WithMessage(fmt.Sprintf("variant id %d not found for product %d", item.VariantID, item.ProductID)).
+				WithDetails(map[string]int{"product_id": item.ProductID, "variant_id": item.VariantID})
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(attributes, &d.VariantAttributes); err != nil {
+			return err
+		}
+		d.VariantID = &item.VariantID
+		return nil
+	}
+
+	var hasVariants bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT p.nama, p.harga, p.harga_pokok, p.category_id, COALESCE(c.name, ''),
+		       EXISTS (
+		           SELECT 1 FROM product_variants v
+		           WHERE v.product_id = p.id AND v.deleted_at IS NULL
+		       )
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+		FOR UPDATE OF p
+	`, item.ProductID).Scan(&d.ProductName, &d.UnitPrice, &d.UnitCost, &d.CategoryID, &d.CategoryName, &hasVariants)
+
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound.
+			WithMessage(fmt.Sprintf("product id %d not found", item.ProductID)).
+			WithDetails(map[string]int{"product_id": item.ProductID})
+	}
+	if err != nil {
+		return err
+	}
+	if hasVariants {
+		return ErrVariantRequired.WithDetails(map[string]int{"product_id": item.ProductID})
+	}
+
+	return nil
+}
+
+func reserveIdempotencyKey(
+	ctx context.Context,
+	tx *sql.Tx,
+	k *model.IdempotencyKey,
+) error {
+
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE key = $1 AND expires_at <= NOW()
+	`, k.Key)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (key, request_hash, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO NOTHING
+	`, k.Key, k.RequestHash, k.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return ErrIdempotencyKeyExists
+	}
+
+	return nil
+}
+
+// key yang belum expired & sudah punya transaksi; nil kalau tidak ada
+func (r *transactionRepository) FindIdempotencyKey(
+	ctx context.Context,
+	key string,
+) (*model.IdempotencyKey, error) {
+
+	var k model.IdempotencyKey
+	err := r.db.QueryRowContext(ctx, `
+		SELECT key, request_hash, transaction_id, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1
+		  AND expires_at > NOW()
+		  AND transaction_id IS NOT NULL
+	`, key).Scan(
+		&k.Key,
+		&k.RequestHash,
+		&k.TransactionID,
+		&k.CreatedAt,
+		&k.ExpiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+func (r *transactionRepository) DeleteExpiredIdempotencyKeys(
+	ctx context.Context,
+) (int64, error) {
+
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= NOW()
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// =====================================================
+// ALLOCATE PAYMENTS
+// - total tender harus >= total_amount
+// - kembalian hanya dari cash (card/qris/ewallet tidak boleh lebih)
+// - tanpa payments = transaksi lama (tidak dicatat)
+// =====================================================
+func allocatePayments(
+	totalAmount int,
+	input []model.CheckoutPayment,
+) ([]model.Payment, int, int, error) {
+
+	if len(input) == 0 {
+		return nil, 0, 0, nil
+	}
+
+	var paid, cash int
+	for _, p := range input {
+		paid += p.Amount
+		if p.Method == model.PaymentMethodCash {
+			cash += p.Amount
+		}
+	}
+
+	if paid < totalAmount {
+		return nil, 0, 0, ErrPaymentInsufficient.
+			WithMessage(fmt.Sprintf(
+				"payments do not cover total amount (total %d, paid %d)",
+				totalAmount, paid,
+			)).
+			WithDetails(map[string]int{"total_amount": totalAmount, "paid_amount": paid})
+	}
+
+	change := paid - totalAmount
+	if change > cash {
+		return nil, 0, 0, ErrNonCashOverpayment
+	}
+
+	// kembalian dipotong dari cash, mulai dari tender cash terakhir
+	payments := make([]model.Payment, len(input))
+	remaining := change
+	for i := len(input) - 1; i >= 0; i-- {
+		p := input[i]
+		payments[i] = model.Payment{
+			Method:   p.Method,
+			Amount:   p.Amount,
+			Tendered: p.Amount,
+		}
+
+		if p.Method == model.PaymentMethodCash && remaining > 0 {
+			take := min(p.Amount, remaining)
+			payments[i].Amount -= take
+			remaining -= take
+		}
+	}
+
+	return payments, paid, change, nil
+}
+
+var transactionSort = sortSpec{
+	fields: map[string]sortField{
+		"id":           {column: "id", sqlType: "integer"},
+		"created_at":   {column: "created_at", sqlType: "timestamptz"},
+		"total_amount": {column: "total_amount", sqlType: "integer"},
+	},
+	idColumn:    "id",
+	defaultSort: "created_at",
+	defaultDesc: true,
+}
+
 func (r *transactionRepository) FindAll(
 	ctx context.Context,
-) ([]model.Transaction, error) {
+	page model.PageRequest,
+) (*model.Page[model.Transaction], error) {
+
+	pc, err := buildPage(page, transactionSort, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int
+	err = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM transactions`).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
 
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, total_amount, created_at
+		SELECT
+			id,
+			total_amount,
+			paid_amount,
+			change_amount,
+			status,
+			outlet_id,
+			user_id,
+			created_at,
+			`+pc.sortExpr+`
 		FROM transactions
-		ORDER BY created_at DESC
-	`)
+		WHERE 1=1`+pc.where+pc.orderBy,
+		pc.args...,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var transactions []model.Transaction
+	var (
+		transactions []model.Transaction
+		sortValues   []string
+	)
 	for rows.Next() {
-		var t model.Transaction
+		var (
+			t         model.Transaction
+			sortValue string
+		)
 
 		// 🔑 INIT SLICE → JSON jadi []
 		t.Details = []model.TransactionDetail{}
@@ -181,15 +620,25 @@ func (r *transactionRepository) FindAll(
 		if err := rows.Scan(
 			&t.ID,
 			&t.TotalAmount,
+			&t.PaidAmount,
+			&t.ChangeAmount,
+			&t.Status,
+			&t.OutletID,
+			&t.UserID,
 			&t.CreatedAt,
+			&sortValue,
 		); err != nil {
 			return nil, err
 		}
 
 		transactions = append(transactions, t)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return transactions, nil
+	return newPage(pc, transactions, sortValues, total, func(t model.Transaction) int { return t.ID }), nil
 }
 
 func (r *transactionRepository) FindByID(
@@ -199,13 +648,22 @@ func (r *transactionRepository) FindByID(
 
 	var t model.Transaction
 	err := r.db.QueryRowContext(ctx, `
-		SELECT id, total_amount, created_at
+		SELECT id, total_amount, paid_amount, change_amount, status, outlet_id, user_id, created_at
 		FROM transactions
 		WHERE id = $1
-	`, id).Scan(&t.ID, &t.TotalAmount, &t.CreatedAt)
+	`, id).Scan(
+		&t.ID,
+		&t.TotalAmount,
+		&t.PaidAmount,
+		&t.ChangeAmount,
+		&t.Status,
+		&t.OutletID,
+		&t.UserID,
+		&t.CreatedAt,
+	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("transaction not found")
+		return nil, ErrTransactionNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -216,11 +674,16 @@ func (r *transactionRepository) FindByID(
 			td.id,
 			td.transaction_id,
 			td.product_id,
-			p.nama,
+			td.product_name,
+			td.category_id,
+			td.category_name,
+			td.unit_price,
+			td.unit_cost,
 			td.quantity,
-			td.subtotal
+			td.subtotal,
+			td.variant_id,
+			td.variant_attributes
 		FROM transaction_details td
-		JOIN products p ON p.id = td.product_id
 		WHERE td.transaction_id = $1
 		ORDER BY td.id
 	`, id)
@@ -230,19 +693,547 @@ func (r *transactionRepository) FindByID(
 	defer rows.Close()
 
 	for rows.Next() {
-		var d model.TransactionDetail
+		var (
+			d          model.TransactionDetail
+			attributes []byte
+		)
 		if err := rows.Scan(
 			&d.ID,
 			&d.TransactionID,
 			&d.ProductID,
 			&d.ProductName,
+			&d.CategoryID,
+			&d.CategoryName,
+			&d.UnitPrice,
+			&d.UnitCost,
 			&d.Quantity,
 			&d.Subtotal,
+			&d.VariantID,
+			&attributes,
 		); err != nil {
 			return nil, err
 		}
+		if attributes != nil {
+			if err := json.Unmarshal(attributes, &d.VariantAttributes); err != nil {
+				return nil, err
+			}
+		}
 		t.Details = append(t.Details, d)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	payments, err := r.findPayments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.Payments = payments
+
+	refunds, err := r.findRefunds(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.Refunds = refunds
 
 	return &t, nil
 }
+
+// =====================================================
+// EXPORT (streaming, satu baris per detail line)
+// - Start / End zero = tanpa batas tanggal
+// - fn dipanggil per baris, tidak ada yang ditampung di memory
+// =====================================================
+func (r *transactionRepository) ExportLines(
+	ctx context.Context,
+	f model.ReportFilter,
+	fn func(model.TransactionExportLine) error,
+) error {
+
+	var start, end *time.Time
+	if !f.Start.IsZero() {
+		start = &f.Start
+	}
+	if !f.End.IsZero() {
+		end = &f.End
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			t.id,
+			t.created_at,
+			t.status,
+			t.outlet_id,
+			COALESCE(o.name, ''),
+			COALESCE(u.username, ''),
+			t.total_amount,
+			COALESCE((
+				SELECT STRING_AGG(tp.method || ':' || tp.amount, ' ' ORDER BY tp.id)
+				FROM transaction_payments tp
+				WHERE tp.transaction_id = t.id
+			), ''),
+			td.id,
+			td.product_id,
+			td.product_name,
+			td.category_id,
+			td.category_name,
+			td.unit_price,
+			td.unit_cost,
+			td.quantity,
+			td.subtotal,
+			td.variant_id,
+			td.variant_attributes,
+			COALESCE(rf.qty, 0),
+			COALESCE(rf.amount, 0)
+		FROM transactions t
+		JOIN transaction_details td ON td.transaction_id = t.id
+		LEFT JOIN outlets o ON o.id = t.outlet_id
+		LEFT JOIN users u ON u.id = t.user_id
+		LEFT JOIN LATERAL (
+			SELECT SUM(ri.quantity) AS qty, SUM(ri.amount) AS amount
+			FROM refund_items ri
+			WHERE ri.transaction_detail_id = td.id
+		) rf ON TRUE
+		WHERE ($1::timestamptz IS NULL OR t.created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR t.created_at < $2)
+		  AND ($3::integer IS NULL OR t.outlet_id = $3)
+		ORDER BY t.created_at, t.id, td.id
+	`, start, end, f.OutletID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			l          model.TransactionExportLine
+			attributes []byte
+		)
+		if err := rows.Scan(
+			&l.TransactionID,
+			&l.CreatedAt,
+			&l.Status,
+			&l.OutletID,
+			&l.OutletName,
+			&l.Cashier,
+			&l.TotalAmount,
+			&l.Payments,
+			&l.Detail.ID,
+			&l.Detail.ProductID,
+			&l.Detail.ProductName,
+			&l.Detail.CategoryID,
+			&l.Detail.CategoryName,
+			&l.Detail.UnitPrice,
+			&l.Detail.UnitCost,
+			&l.Detail.Quantity,
+			&l.Detail.Subtotal,
+			&l.Detail.VariantID,
+			&attributes,
+			&l.RefundedQty,
+			&l.RefundedTotal,
+		); err != nil {
+			return err
+		}
+		l.Detail.TransactionID = l.TransactionID
+		if attributes != nil {
+			if err := json.Unmarshal(attributes, &l.Detail.VariantAttributes); err != nil {
+				return err
+			}
+		}
+
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *transactionRepository) findPayments(
+	ctx context.Context,
+	transactionID int,
+) ([]model.Payment, error) {
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, transaction_id, method, amount, tendered
+		FROM transaction_payments
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []model.Payment
+	for rows.Next() {
+		var p model.Payment
+		if err := rows.Scan(
+			&p.ID,
+			&p.TransactionID,
+			&p.Method,
+			&p.Amount,
+			&p.Tendered,
+		); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+func (r *transactionRepository) findRefunds(
+	ctx context.Context,
+	transactionID int,
+) ([]model.Refund, error) {
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			rf.id,
+			rf.type,
+			rf.reason,
+			rf.total_amount,
+			rf.user_id,
+			rf.created_at,
+			ri.id,
+			ri.transaction_detail_id,
+			ri.product_id,
+			ri.quantity,
+			ri.amount
+		FROM refunds rf
+		JOIN refund_items ri ON ri.refund_id = rf.id
+		WHERE rf.transaction_id = $1
+		ORDER BY rf.id, ri.id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []model.Refund
+	for rows.Next() {
+		var (
+			rf   model.Refund
+			item model.RefundItem
+		)
+		if err := rows.Scan(
+			&rf.ID,
+			&rf.Type,
+			&rf.Reason,
+			&rf.TotalAmount,
+			&rf.UserID,
+			&rf.CreatedAt,
+			&item.ID,
+			&item.TransactionDetailID,
+			&item.ProductID,
+			&item.Quantity,
+			&item.Amount,
+		); err != nil {
+			return nil, err
+		}
+		item.RefundID = rf.ID
+
+		if n := len(refunds); n == 0 || refunds[n-1].ID != rf.ID {
+			rf.TransactionID = transactionID
+			refunds = append(refunds, rf)
+		}
+		last := &refunds[len(refunds)-1]
+		last.Items = append(last.Items, item)
+	}
+
+	return refunds, rows.Err()
+}
+
+// =====================================================
+// REFUND (full / sebagian item)
+// =====================================================
+func (r *transactionRepository) Refund(
+	ctx context.Context,
+	transactionID int,
+	req model.RefundRequest,
+) (*model.Refund, error) {
+	return r.reverse(ctx, transactionID, model.RefundTypeRefund, req.Reason, req.Items)
+}
+
+// =====================================================
+// VOID (batalkan seluruh transaksi, belum boleh ada refund)
+// =====================================================
+func (r *transactionRepository) Void(
+	ctx context.Context,
+	transactionID int,
+	reason string,
+) (*model.Refund, error) {
+	return r.reverse(ctx, transactionID, model.RefundTypeVoid, reason, nil)
+}
+
+// sisa item yang masih bisa di-refund per transaction_detail
+type refundableLine struct {
+	detailID       int
+	productID      int
+	variantID      *int
+	quantity       int
+	subtotal       int
+	refundedQty    int
+	refundedAmount int
+	requestedQty   int
+}
+
+// =====================================================
+// REVERSE
+// - satu sql.Tx: lock, hitung sisa, insert refund, kembalikan stok
+// =====================================================
+func (r *transactionRepository) reverse(
+	ctx context.Context,
+	transactionID int,
+	refundType string,
+	reason string,
+	items []model.RefundRequestItem,
+) (*model.Refund, error) {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 🔒 lock transaction header (stok kembali ke outlet transaksi)
+	var (
+		status   string
+		outletID int
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT status, outlet_id
+		FROM transactions
+		WHERE id = $1
+		FOR UPDATE
+	`, transactionID).Scan(&status, &outletID)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case status == model.TransactionStatusVoided:
+		return nil, ErrTransactionVoided
+	case status == model.TransactionStatusRefunded:
+		return nil, ErrTransactionRefunded
+	case refundType == model.RefundTypeVoid && status != model.TransactionStatusCompleted:
+		return nil, ErrVoidAfterRefund
+	}
+
+	// ==========================
+	// SISA QTY PER DETAIL
+	// ==========================
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			td.id,
+			td.product_id,
+			td.variant_id,
+			td.quantity,
+			td.subtotal,
+			COALESCE(SUM(ri.quantity), 0),
+			COALESCE(SUM(ri.amount), 0)
+		FROM transaction_details td
+		LEFT JOIN refund_items ri ON ri.transaction_detail_id = td.id
+		WHERE td.transaction_id = $1
+		GROUP BY td.id
+		ORDER BY td.id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []refundableLine
+	for rows.Next() {
+		var l refundableLine
+		if err := rows.Scan(
+			&l.detailID,
+			&l.productID,
+			&l.variantID,
+			&l.quantity,
+			&l.subtotal,
+			&l.refundedQty,
+			&l.refundedAmount,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := applyRefundRequest(lines, items); err != nil {
+		return nil, err
+	}
+
+	// ==========================
+	// INSERT REFUND (HEADER)
+	// ==========================
+	refund := model.Refund{
+		TransactionID: transactionID,
+		Type:          refundType,
+		Reason:        reason,
+		UserID:        auth.UserIDFromContext(ctx),
+		Items:         make([]model.RefundItem, 0),
+	}
+
+	// stok kembali ke varian yang terjual (sejajar dengan refund.Items)
+	var variantIDs []*int
+
+	for _, l := range lines {
+		if l.requestedQty == 0 {
+			continue
+		}
+
+		// item terakhir ambil sisa subtotal supaya tidak ada selisih pembulatan
+		amount := l.subtotal * l.requestedQty / l.quantity
+		if l.refundedQty+l.requestedQty == l.quantity {
+			amount = l.subtotal - l.refundedAmount
+		}
+
+		refund.TotalAmount += amount
+		refund.Items = append(refund.Items, model.RefundItem{
+			TransactionDetailID: l.detailID,
+			ProductID:           l.productID,
+			Quantity:            l.requestedQty,
+			Amount:              amount,
+		})
+		variantIDs = append(variantIDs, l.variantID)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO refunds (transaction_id, type, reason, total_amount, user_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`,
+		transactionID,
+		refundType,
+		reason,
+		refund.TotalAmount,
+		refund.UserID,
+	).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// ==========================
+	// INSERT ITEMS + KEMBALIKAN STOK (ledger: refund)
+	// ==========================
+	for i := range refund.Items {
+		item := &refund.Items[i]
+		item.RefundID = refund.ID
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO refund_items
+				(refund_id, transaction_detail_id, product_id, quantity, amount)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`,
+			refund.ID,
+			item.TransactionDetailID,
+			item.ProductID,
+			item.Quantity,
+			item.Amount,
+		).Scan(&item.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = applyStockChange(ctx, tx, &model.StockMovement{
+			ProductID:   item.ProductID,
+			VariantID:   variantIDs[i],
+			OutletID:    outletID,
+			Type:        model.StockMovementRefund,
+			Quantity:    item.Quantity,
+			Reason:      refundType + ": " + reason,
+			ReferenceID: &refund.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// ==========================
+	// UPDATE STATUS TRANSAKSI
+	// ==========================
+	newStatus := model.TransactionStatusRefunded
+	if refundType == model.RefundTypeVoid {
+		newStatus = model.TransactionStatusVoided
+	} else {
+		for _, l := range lines {
+			if l.refundedQty+l.requestedQty < l.quantity {
+				newStatus = model.TransactionStatusPartiallyRefunded
+				break
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE transactions
+		SET status = $1
+		WHERE id = $2
+	`, newStatus, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// isi requestedQty per line; items kosong = semua sisa
+func applyRefundRequest(lines []refundableLine, items []model.RefundRequestItem) error {
+	if len(items) == 0 {
+		for i := range lines {
+			lines[i].requestedQty = lines[i].quantity - lines[i].refundedQty
+		}
+		return nil
+	}
+
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return ErrInvalidRefundQty
+		}
+
+		remaining := item.Quantity
+		matched := false
+		for i := range lines {
+			l := &lines[i]
+			if item.TransactionDetailID != 0 && l.detailID != item.TransactionDetailID {
+				continue
+			}
+			if item.TransactionDetailID == 0 && l.productID != item.ProductID {
+				continue
+			}
+			matched = true
+
+			available := l.quantity - l.refundedQty - l.requestedQty
+			take := min(available, remaining)
+			l.requestedQty += take
+			remaining -= take
+
+			if remaining == 0 {
+				break
+			}
+		}
+
+		if !matched {
+			return ErrRefundItemNotFound
+		}
+		if remaining > 0 {
+			return ErrRefundExceedsSold
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+func TestAllocatePayments(t *testing.T) {
+	cash := func(amount int) model.CheckoutPayment {
+		return model.CheckoutPayment{Method: model.PaymentMethodCash, Amount: amount}
+	}
+	card := func(amount int) model.CheckoutPayment {
+		return model.CheckoutPayment{Method: model.PaymentMethodCard, Amount: amount}
+	}
+
+	tests := []struct {
+		name       string
+		total      int
+		input      []model.CheckoutPayment
+		want       []model.Payment
+		wantPaid   int
+		wantChange int
+		wantErr    error
+	}{
+		{
+			name:  "tanpa payments",
+			total: 10000,
+		},
+		{
+			name:     "pas",
+			total:    10000,
+			input:    []model.CheckoutPayment{cash(10000)},
+			want:     []model.Payment{{Method: "cash", Amount: 10000, Tendered: 10000}},
+			wantPaid: 10000,
+		},
+		{
+			name:  "split tender card + cash",
+			total: 25000,
+			input: []model.CheckoutPayment{card(15000), cash(10000)},
+			want: []model.Payment{
+				{Method: "card", Amount: 15000, Tendered: 15000},
+				{Method: "cash", Amount: 10000, Tendered: 10000},
+			},
+			wantPaid: 25000,
+		},
+		{
+			name:       "kembalian dari cash",
+			total:      23000,
+			input:      []model.CheckoutPayment{cash(50000)},
+			want:       []model.Payment{{Method: "cash", Amount: 23000, Tendered: 50000}},
+			wantPaid:   50000,
+			wantChange: 27000,
+		},
+		{
+			name:  "kembalian dipotong dari cash terakhir dulu",
+			total: 12000,
+			input: []model.CheckoutPayment{cash(10000), card(2000), cash(5000)},
+			want: []model.Payment{
+				{Method: "cash", Amount: 10000, Tendered: 10000},
+				{Method: "card", Amount: 2000, Tendered: 2000},
+				{Method: "cash", Amount: 0, Tendered: 5000},
+			},
+			wantPaid:   17000,
+			wantChange: 5000,
+		},
+		{
+			name:  "kembalian melewati satu tender cash",
+			total: 8000,
+			input: []model.CheckoutPayment{cash(10000), cash(3000)},
+			want: []model.Payment{
+				{Method: "cash", Amount: 8000, Tendered: 10000},
+				{Method: "cash", Amount: 0, Tendered: 3000},
+			},
+			wantPaid:   13000,
+			wantChange: 5000,
+		},
+		{
+			name:    "kurang bayar",
+			total:   20000,
+			input:   []model.CheckoutPayment{cash(5000), card(10000)},
+			wantErr: ErrPaymentInsufficient,
+		},
+		{
+			name:    "non-cash lebih bayar",
+			total:   20000,
+			input:   []model.CheckoutPayment{card(25000)},
+			wantErr: ErrNonCashOverpayment,
+		},
+		{
+			name:    "kembalian lebih besar dari cash",
+			total:   20000,
+			input:   []model.CheckoutPayment{card(19000), cash(2000), card(5000)},
+			wantErr: ErrNonCashOverpayment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, paid, change, err := allocatePayments(tt.total, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("payments = %+v, want %+v", got, tt.want)
+			}
+			if paid != tt.wantPaid || change != tt.wantChange {
+				t.Errorf("paid, change = %d, %d, want %d, %d", paid, change, tt.wantPaid, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestApplyRefundRequest(t *testing.T) {
+	// produk 10 muncul di dua line (mis. beda varian)
+	newLines := func() []refundableLine {
+		return []refundableLine{
+			{detailID: 1, productID: 10, quantity: 3},
+			{detailID: 2, productID: 20, quantity: 2, refundedQty: 1},
+			{detailID: 3, productID: 10, quantity: 4},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		items   []model.RefundRequestItem
+		want    []int // requestedQty per line
+		wantErr error
+	}{
+		{
+			name: "items kosong = semua sisa",
+			want: []int{3, 1, 4},
+		},
+		{
+			name:  "partial by detail id",
+			items: []model.RefundRequestItem{{TransactionDetailID: 3, Quantity: 2}},
+			want:  []int{0, 0, 2},
+		},
+		{
+			name:  "by product id dibagi ke beberapa line",
+			items: []model.RefundRequestItem{{ProductID: 10, Quantity: 5}},
+			want:  []int{3, 0, 2},
+		},
+		{
+			name: "item berulang untuk line yang sama",
+			items: []model.RefundRequestItem{
+				{TransactionDetailID: 1, Quantity: 1},
+				{TransactionDetailID: 1, Quantity: 2},
+			},
+			want: []int{3, 0, 0},
+		},
+		{
+			name:    "melebihi qty terjual",
+			items:   []model.RefundRequestItem{{TransactionDetailID: 1, Quantity: 4}},
+			wantErr: ErrRefundExceedsSold,
+		},
+		{
+			name:    "melebihi sisa setelah refund sebelumnya",
+			items:   []model.RefundRequestItem{{ProductID: 20, Quantity: 2}},
+			wantErr: ErrRefundExceedsSold,
+		},
+		{
+			name:    "by product id melebihi total semua line",
+			items:   []model.RefundRequestItem{{ProductID: 10, Quantity: 8}},
+			wantErr: ErrRefundExceedsSold,
+		},
+		{
+			name:    "qty nol",
+			items:   []model.RefundRequestItem{{TransactionDetailID: 1, Quantity: 0}},
+			wantErr: ErrInvalidRefundQty,
+		},
+		{
+			name:    "item tidak ada di transaksi",
+			items:   []model.RefundRequestItem{{ProductID: 99, Quantity: 1}},
+			wantErr: ErrRefundItemNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := newLines()
+			err := applyRefundRequest(lines, tt.items)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			got := make([]int, len(lines))
+			for i, l := range lines {
+				got[i] = l.requestedQty
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requestedQty = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
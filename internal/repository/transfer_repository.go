@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/auth"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+var (
+	ErrTransferNotFound     = apperror.NotFound("TRANSFER_NOT_FOUND", "stock transfer not found")
+	ErrTransferNotInTransit = apperror.Conflict("TRANSFER_NOT_IN_TRANSIT", "stock transfer is no longer in transit")
+)
+
+type TransferRepository interface {
+	FindAll(ctx context.Context, status string, page model.PageRequest) (*model.Page[model.StockTransfer], error)
+	FindByID(ctx context.Context, id int) (*model.StockTransfer, error)
+	Create(ctx context.Context, t *model.StockTransfer) error
+	Receive(ctx context.Context, id int) error
+	Cancel(ctx context.Context, id int) error
+}
+
+type transferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) TransferRepository {
+	return &transferRepository{db: db}
+}
+
+var transferSort = sortSpec{
+	fields: map[string]sortField{
+		"id":         {column: "id", sqlType: "integer"},
+		"created_at": {column: "created_at", sqlType: "timestamptz"},
+	},
+	idColumn:    "id",
+	defaultSort: "created_at",
+	defaultDesc: true,
+}
+
+const transferColumns = `
+	id, from_outlet_id, to_outlet_id, status, note, user_id, created_at, received_at, cancelled_at
+`
+
+// list tanpa items (detail lewat FindByID)
+func (r *transferRepository) FindAll(
+	ctx context.Context,
+	status string,
+	page model.PageRequest,
+) (*model.Page[model.StockTransfer], error) {
+
+	where := &whereBuilder{}
+	if status != "" {
+		where.add("status = ?", status)
+	}
+
+	pc, err := buildPage(page, transferSort, where.nextPos())
+	if err != nil {
+		return nil, err
+	}
+
+	var total int
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM stock_transfers
+		WHERE 1=1`+where.String(),
+		where.args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+transferColumns+`, `+pc.sortExpr+`
+		FROM stock_transfers
+		WHERE 1=1`+where.String()+pc.where+pc.orderBy,
+		append(where.args, pc.args...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		transfers  []model.StockTransfer
+		sortValues []string
+	)
+	for rows.Next() {
+		var (
+			t         model.StockTransfer
+			sortValue string
+		)
+		if err := rows.Scan(
+			&t.ID,
+			&t.FromOutletID,
+			&t.ToOutletID,
+			&t.Status,
+			&t.Note,
+			&t.UserID,
+			&t.CreatedAt,
+			&t.ReceivedAt,
+			&t.CancelledAt,
+			&sortValue,
+		); err != nil {
+			return nil, err
+		}
+		t.Items = []model.StockTransferItem{}
+
+		transfers = append(transfers, t)
+		sortValues = append(sortValues, sortValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return newPage(pc, transfers, sortValues, total, func(t model.StockTransfer) int { return t.ID }), nil
+}
+
+func (r *transferRepository) FindByID(ctx context.Context, id int) (*model.StockTransfer, error) {
+	var t model.StockTransfer
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+transferColumns+`
+		FROM stock_transfers
+		WHERE id = $1
+	`, id).Scan(
+		&t.ID,
+		&t.FromOutletID,
+		&t.ToOutletID,
+		&t.Status,
+		&t.Note,
+		&t.UserID,
+		&t.CreatedAt,
+		&t.ReceivedAt,
+		&t.CancelledAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, transfer_id, product_id, variant_id, quantity
+		FROM stock_transfer_items
+		WHERE transfer_id = $1
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Items = make([]model.StockTransferItem, 0)
+	for rows.Next() {
+		var item model.StockTransferItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.TransferID,
+			&item.ProductID,
+			&item.VariantID,
+			&item.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		t.Items = append(t.Items, item)
+	}
+
+	return &t, rows.Err()
+}
+
+// =====================================================
+// CREATE TRANSFER (status in_transit)
+// stok langsung keluar dari outlet asal (ledger: transfer_out)
+// =====================================================
+func (r *transferRepository) Create(ctx context.Context, t *model.StockTransfer) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := resolveOutlet(ctx, tx, t.FromOutletID); err != nil {
+		return err
+	}
+	if _, err := resolveOutlet(ctx, tx, t.ToOutletID); err != nil {
+		return err
+	}
+
+	t.Status = model.TransferStatusInTransit
+	t.UserID = auth.UserIDFromContext(ctx)
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO stock_transfers (from_outlet_id, to_outlet_id, status, note, user_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`,
+		t.FromOutletID,
+		t.ToOutletID,
+		t.Status,
+		t.Note,
+		t.UserID,
+	).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range t.Items {
+		item := &t.Items[i]
+		item.TransferID = t.ID
+
+		// 🔒 lock product / variant, lalu stok outlet asal
+		if _, err := lockStock(ctx, tx, item.ProductID, item.VariantID); err != nil {
+			return err
+		}
+		stock, err := lockOutletStock(ctx, tx, t.FromOutletID, item.ProductID, item.VariantID)
+		if err != nil {
+			return err
+		}
+
+		if stock < item.Quantity {
+			return ErrInsufficientStock.
+				WithMessage(fmt.Sprintf(
+					"stock not enough for product %d at outlet %d (available %d)",
+					item.ProductID, t.FromOutletID, stock,
+				)).
+				WithDetails(map[string]int{
+					"product_id": item.ProductID,
+					"outlet_id":  t.FromOutletID,
+					"available":  stock,
+					"requested":  item.Quantity,
+				})
+		}
+
+		err = applyStockChange(ctx, tx, &model.StockMovement{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			OutletID:    t.FromOutletID,
+			Type:        model.StockMovementTransferOut,
+			Quantity:    -item.Quantity,
+			Reason:      fmt.Sprintf("transfer to outlet %d", t.ToOutletID),
+			ReferenceID: &t.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO stock_transfer_items (transfer_id, product_id, variant_id, quantity)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`,
+			t.ID,
+			item.ProductID,
+			item.VariantID,
+			item.Quantity,
+		).Scan(&item.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// =====================================================
+// RECEIVE: stok masuk ke outlet tujuan (ledger: transfer_in)
+// =====================================================
+func (r *transferRepository) Receive(ctx context.Context, id int) error {
+	return r.complete(ctx, id, model.TransferStatusReceived)
+}
+
+// =====================================================
+// CANCEL: stok kembali ke outlet asal (ledger: transfer_in)
+// =====================================================
+func (r *transferRepository) Cancel(ctx context.Context, id int) error {
+	return r.complete(ctx, id, model.TransferStatusCancelled)
+}
+
+// in_transit → received / cancelled dalam satu sql.Tx
+func (r *transferRepository) complete(ctx context.Context, id int, status string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 🔒 lock transfer header
+	var (
+		current              string
+		fromOutlet, toOutlet int
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT status, from_outlet_id, to_outlet_id
+		FROM stock_transfers
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&current, &fromOutlet, &toOutlet)
+
+	if err == sql.ErrNoRows {
+		return ErrTransferNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if current != model.TransferStatusInTransit {
+		return ErrTransferNotInTransit.WithDetails(map[string]string{"status": current})
+	}
+
+	outletID, reason, column := toOutlet, "transfer received", "received_at"
+	if status == model.TransferStatusCancelled {
+		outletID, reason, column = fromOutlet, "transfer cancelled", "cancelled_at"
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT product_id, variant_id, quantity
+		FROM stock_transfer_items
+		WHERE transfer_id = $1
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	var items []model.StockTransferItem
+	for rows.Next() {
+		var item model.StockTransferItem
+		if err := rows.Scan(&item.ProductID, &item.VariantID, &item.Quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		err = applyStockChange(ctx, tx, &model.StockMovement{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			OutletID:    outletID,
+			Type:        model.StockMovementTransferIn,
+			Quantity:    item.Quantity,
+			Reason:      reason,
+			ReferenceID: &id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE stock_transfers
+		SET status = $1, `+column+` = NOW()
+		WHERE id = $2
+	`, status, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
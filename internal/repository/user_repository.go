@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+var (
+	ErrUserNotFound  = apperror.NotFound("USER_NOT_FOUND", "user not found")
+	ErrUsernameTaken = apperror.Conflict("USERNAME_TAKEN", "username already exists")
+)
+
+type UserRepository interface {
+	FindAll(ctx context.Context) ([]model.User, error)
+	FindByID(ctx context.Context, id int) (*model.User, error)
+	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	Create(ctx context.Context, u *model.User) error
+	Count(ctx context.Context) (int, error)
+}
+
+type userRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) FindAll(ctx context.Context) ([]model.User, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, username, password_hash, role, active, created_at
+		FROM users
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0)
+	for rows.Next() {
+		var u model.User
+		if err := rows.Scan(
+			&u.ID,
+			&u.Username,
+			&u.PasswordHash,
+			&u.Role,
+			&u.Active,
+			&u.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id int) (*model.User, error) {
+	return r.findOne(ctx, `WHERE id = $1`, id)
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	return r.findOne(ctx, `WHERE username = $1`, username)
+}
+
+func (r *userRepository) findOne(ctx context.Context, where string, arg any) (*model.User, error) {
+	var u model.User
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, username, password_hash, role, active, created_at
+		FROM users
+		`+where, arg).Scan(
+		&u.ID,
+		&u.Username,
+		&u.PasswordHash,
+		&u.Role,
+		&u.Active,
+		&u.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, u *model.User) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO users (username, password_hash, role, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, u.Username, u.PasswordHash, u.Role, u.Active).Scan(&u.ID, &u.CreatedAt)
+
+	if isUniqueViolation(err) {
+		return ErrUsernameTaken
+	}
+
+	return err
+}
+
+func (r *userRepository) Count(ctx context.Context) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&n)
+	return n, err
+}
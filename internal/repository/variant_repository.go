@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/lib/pq"
+)
+
+type VariantRepository interface {
+	FindByProduct(ctx context.Context, productID int) ([]model.ProductVariant, error)
+	FindByID(ctx context.Context, productID, variantID int) (*model.ProductVariant, error)
+	Create(ctx context.Context, v *model.ProductVariant) error
+	Update(ctx context.Context, v *model.ProductVariant) error
+	Delete(ctx context.Context, productID, variantID int) error
+}
+
+type variantRepository struct {
+	db *sql.DB
+}
+
+func NewVariantRepository(db *sql.DB) VariantRepository {
+	return &variantRepository{db: db}
+}
+
+const variantColumns = `
+	id, product_id, COALESCE(sku, ''), attributes, harga, stok, active, created_at, updated_at
+`
+
+func (r *variantRepository) FindByProduct(ctx context.Context, productID int) ([]model.ProductVariant, error) {
+	if err := productExists(ctx, r.db, productID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+variantColumns+`
+		FROM product_variants
+		WHERE product_id = $1 AND deleted_at IS NULL
+		ORDER BY id
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := make([]model.ProductVariant, 0)
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, *v)
+	}
+
+	return variants, rows.Err()
+}
+
+func (r *variantRepository) FindByID(ctx context.Context, productID, variantID int) (*model.ProductVariant, error) {
+	v, err := scanVariant(r.db.QueryRowContext(ctx, `
+		SELECT `+variantColumns+`
+		FROM product_variants
+		WHERE id = $1 AND product_id = $2 AND deleted_at IS NULL
+	`, variantID, productID))
+
+	if err == sql.ErrNoRows {
+		return nil, ErrVariantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// =====================================================
+// CREATE VARIANT
+// stok awal dicatat ke ledger varian sebagai receiving (outlet default)
+// =====================================================
+func (r *variantRepository) Create(ctx context.Context, v *model.ProductVariant) error {
+	attributes, err := json.Marshal(v.Attributes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 🔒 lock product (tidak boleh di-soft delete bersamaan)
+	err = tx.QueryRowContext(ctx, `
+		SELECT id
+		FROM products
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, v.ProductID).Scan(&v.ProductID)
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO product_variants
+			(product_id, sku, attributes, harga, active)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`,
+		v.ProductID,
+		v.SKU,
+		attributes,
+		v.Harga,
+		v.Active,
+	).Scan(&v.ID, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return variantUniqueError(err)
+	}
+
+	// stok awal masuk ke outlet default
+	if v.Stok != 0 {
+		err = applyStockChange(ctx, tx, &model.StockMovement{
+			ProductID: v.ProductID,
+			VariantID: &v.ID,
+			Type:      model.StockMovementReceiving,
+			Quantity:  v.Stok,
+			Reason:    "initial stock",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// =====================================================
+// UPDATE VARIANT
+// - perubahan stok dicatat ke ledger sebagai adjustment (outlet default)
+// =====================================================
+func (r *variantRepository) Update(ctx context.Context, v *model.ProductVariant) error {
+	attributes, err := json.Marshal(v.Attributes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 🔒 lock variant row
+	stock, err := lockStock(ctx, tx, v.ProductID, &v.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE product_variants
+		SET sku = NULLIF($1, ''),
+		    attributes = $2,
+		    harga = $3,
+		    active = $4
+		WHERE id = $5
+		RETURNING created_at, updated_at
+	`,
+		v.SKU,
+		attributes,
+		v.Harga,
+		v.Active,
+		v.ID,
+	).Scan(&v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return variantUniqueError(err)
+	}
+
+	if v.Stok != stock {
+		err = applyStockChange(ctx, tx, &model.StockMovement{
+			ProductID: v.ProductID,
+			VariantID: &v.ID,
+			Type:      model.StockMovementAdjustment,
+			Quantity:  v.Stok - stock,
+			Reason:    "variant update",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// soft delete, histori penjualan varian tetap utuh
+func (r *variantRepository) Delete(ctx context.Context, productID, variantID int) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE product_variants
+		SET deleted_at = NOW()
+		WHERE id = $1 AND product_id = $2 AND deleted_at IS NULL
+	`, variantID, productID)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return ErrVariantNotFound
+	}
+
+	return nil
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanVariant(row rowScanner) (*model.ProductVariant, error) {
+	var (
+		v          model.ProductVariant
+		attributes []byte
+	)
+	if err := row.Scan(
+		&v.ID,
+		&v.ProductID,
+		&v.SKU,
+		&attributes,
+		&v.Harga,
+		&v.Stok,
+		&v.Active,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(attributes, &v.Attributes); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func productExists(ctx context.Context, db *sql.DB, productID int) error {
+	var exists bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)
+	`, productID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// isi Variants untuk semua produk sekaligus (satu query)
+func attachVariants(ctx context.Context, db *sql.DB, products []model.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(products))
+	ids := make([]int, len(products))
+	for i := range products {
+		index[products[i].ID] = i
+		ids[i] = products[i].ID
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+variantColumns+`
+		FROM product_variants
+		WHERE product_id = ANY($1) AND deleted_at IS NULL
+		ORDER BY product_id, id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return err
+		}
+		i := index[v.ProductID]
+		products[i].Variants = append(products[i].Variants, *v)
+	}
+
+	return rows.Err()
+}
+
+func variantUniqueError(err error) error {
+	if uniqueViolationConstraint(err) == "product_variants_sku_key" {
+		return ErrSKUTaken
+	}
+	return err
+}
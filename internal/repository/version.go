@@ -0,0 +1,18 @@
+package repository
+
+import "github.com/jackyansen22/crud-category/internal/apperror"
+
+// =====================================================
+// OPTIMISTIC CONCURRENCY
+// kolom version naik otomatis (trigger) di setiap UPDATE,
+// dicek setelah row di-lock FOR UPDATE supaya tidak race
+// =====================================================
+var ErrVersionMismatch = apperror.ErrPreconditionFailed
+
+// expected 0 = request tanpa If-Match, tidak dicek
+func checkVersion(expected, current int) error {
+	if expected != 0 && expected != current {
+		return ErrVersionMismatch
+	}
+	return nil
+}
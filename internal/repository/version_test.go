@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		name              string
+		expected, current int
+		wantErr           error
+	}{
+		{"tanpa If-Match", 0, 5, nil},
+		{"sama", 5, 5, nil},
+		{"sudah diubah", 4, 5, ErrVersionMismatch},
+		{"version dari masa depan", 6, 5, ErrVersionMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkVersion(tt.expected, tt.current); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkVersion(%d, %d) = %v, want %v", tt.expected, tt.current, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// =====================================================
+// WHERE BUILDER
+// kondisi pakai placeholder "?" → diganti $n berurutan,
+// nilai selalu bound parameter (tidak pernah di-concat)
+// =====================================================
+type whereBuilder struct {
+	sql  strings.Builder
+	args []any
+}
+
+func (b *whereBuilder) add(cond string, args ...any) {
+	b.sql.WriteString(" AND ")
+
+	i := 0
+	for _, ch := range cond {
+		if ch == '?' && i < len(args) {
+			b.args = append(b.args, args[i])
+			b.sql.WriteString("$" + strconv.Itoa(len(b.args)))
+			i++
+			continue
+		}
+		b.sql.WriteRune(ch)
+	}
+}
+
+// posisi placeholder berikutnya (untuk pagination dsb.)
+func (b *whereBuilder) nextPos() int {
+	return len(b.args) + 1
+}
+
+func (b *whereBuilder) String() string {
+	return b.sql.String()
+}
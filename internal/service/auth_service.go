@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/auth"
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/repository"
+)
+
+var (
+	ErrInvalidCredentials = apperror.Unauthorized("INVALID_CREDENTIALS", "invalid username or password")
+	ErrInvalidUser        = apperror.Validation("INVALID_USER", "invalid user")
+)
+
+const minPasswordLength = 8
+
+type AuthService interface {
+	Login(ctx context.Context, req model.LoginRequest) (*model.TokenResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*model.TokenResponse, error)
+
+	GetUsers(ctx context.Context) ([]model.User, error)
+	CreateUser(ctx context.Context, req model.CreateUserRequest) (*model.User, error)
+	EnsureAdmin(ctx context.Context, username, password string) (bool, error)
+}
+
+type authService struct {
+	repo   repository.UserRepository
+	tokens *auth.TokenManager
+}
+
+func NewAuthService(repo repository.UserRepository, tokens *auth.TokenManager) AuthService {
+	return &authService{repo: repo, tokens: tokens}
+}
+
+// =====================================================
+// LOGIN: username + password → access & refresh JWT
+// =====================================================
+func (s *authService) Login(
+	ctx context.Context,
+	req model.LoginRequest,
+) (*model.TokenResponse, error) {
+
+	u, err := s.repo.FindByUsername(ctx, req.Username)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.Active {
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issue(u)
+}
+
+// =====================================================
+// REFRESH: user dicek ulang (bisa sudah nonaktif / ganti role)
+// =====================================================
+func (s *authService) Refresh(
+	ctx context.Context,
+	refreshToken string,
+) (*model.TokenResponse, error) {
+
+	claims, err := s.tokens.Parse(refreshToken, auth.TokenRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.repo.FindByID(ctx, claims.UserID())
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !u.Active {
+		return nil, auth.ErrInvalidToken
+	}
+
+	return s.issue(u)
+}
+
+func (s *authService) issue(u *model.User) (*model.TokenResponse, error) {
+	access, refresh, err := s.tokens.Issue(u.ID, u.Username, u.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.tokens.AccessTTL().Seconds()),
+	}, nil
+}
+
+func (s *authService) GetUsers(ctx context.Context) ([]model.User, error) {
+	return s.repo.FindAll(ctx)
+}
+
+func (s *authService) CreateUser(
+	ctx context.Context,
+	req model.CreateUserRequest,
+) (*model.User, error) {
+
+	if req.Username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrInvalidUser)
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, fmt.Errorf("%w: password must be at least %d characters", ErrInvalidUser, minPasswordLength)
+	}
+	if !model.IsValidRole(req.Role) {
+		return nil, fmt.Errorf("%w: role must be admin, manager or cashier", ErrInvalidUser)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &model.User{
+		Username:     req.Username,
+		PasswordHash: string(hash),
+		Role:         req.Role,
+		Active:       true,
+	}
+	if err := s.repo.Create(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// admin pertama dari env (ADMIN_USERNAME / ADMIN_PASSWORD),
+// hanya kalau tabel users masih kosong
+func (s *authService) EnsureAdmin(ctx context.Context, username, password string) (bool, error) {
+	if username == "" || password == "" {
+		return false, nil
+	}
+
+	n, err := s.repo.Count(ctx)
+	if err != nil || n > 0 {
+		return false, err
+	}
+
+	_, err = s.CreateUser(ctx, model.CreateUserRequest{
+		Username: username,
+		Password: password,
+		Role:     model.RoleAdmin,
+	})
+
+	return err == nil, err
+}
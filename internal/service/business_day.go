@@ -0,0 +1,52 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+// =====================================================
+// Batas hari bisnis (timezone + cutoff dari config)
+// dipakai report & export supaya "tanggal" konsisten
+// =====================================================
+type businessDay struct {
+	loc    *time.Location // timezone bisnis default
+	cutoff time.Duration  // awal hari bisnis (mis. 4h = 04:00)
+}
+
+// ?tz= override timezone per request, cutoff selalu dari config
+func (b businessDay) apply(f *model.ReportFilter) {
+	if f.Location == nil {
+		f.Location = b.loc
+	}
+	f.DayCutoff = b.cutoff
+}
+
+// Start / End = hari bisnis yang sedang berjalan
+// (jam 02:00 dengan cutoff 04:00 masih termasuk hari kemarin)
+func (b businessDay) today(f *model.ReportFilter) {
+	b.todayAt(f, time.Now())
+}
+
+func (b businessDay) todayAt(f *model.ReportFilter, now time.Time) {
+	b.apply(f)
+
+	now = now.In(f.Location).Add(-f.DayCutoff)
+	f.Start = dayStart(now, f.Location, f.DayCutoff)
+	f.End = dayStart(now.AddDate(0, 0, 1), f.Location, f.DayCutoff)
+}
+
+// Start / End dari handler = tanggal, diubah ke batas hari bisnis
+func (b businessDay) dateRange(f *model.ReportFilter) {
+	b.apply(f)
+
+	f.Start = dayStart(f.Start, f.Location, f.DayCutoff)
+	f.End = dayStart(f.End, f.Location, f.DayCutoff)
+}
+
+// awal hari bisnis untuk tanggal (year/month/day) dari t
+// cutoff dihitung sebagai jam dinding, jadi tetap benar saat DST
+func dayStart(t time.Time, loc *time.Location, cutoff time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, int(cutoff), loc)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestBusinessDayToday(t *testing.T) {
+	jakarta := mustLoad(t, "Asia/Jakarta")   // UTC+7
+	makassar := mustLoad(t, "Asia/Makassar") // UTC+8
+
+	tests := []struct {
+		name      string
+		days      businessDay
+		override  *time.Location // ?tz=
+		now       time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "tanpa cutoff",
+			days:      businessDay{loc: jakarta},
+			now:       time.Date(2025, 3, 10, 15, 30, 0, 0, jakarta),
+			wantStart: time.Date(2025, 3, 10, 0, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 3, 11, 0, 0, 0, 0, jakarta),
+		},
+		{
+			name:      "02:00 dengan cutoff 04:00 masih hari kemarin",
+			days:      businessDay{loc: jakarta, cutoff: 4 * time.Hour},
+			now:       time.Date(2025, 3, 10, 2, 0, 0, 0, jakarta),
+			wantStart: time.Date(2025, 3, 9, 4, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 3, 10, 4, 0, 0, 0, jakarta),
+		},
+		{
+			name:      "tepat di cutoff = hari baru",
+			days:      businessDay{loc: jakarta, cutoff: 4 * time.Hour},
+			now:       time.Date(2025, 3, 10, 4, 0, 0, 0, jakarta),
+			wantStart: time.Date(2025, 3, 10, 4, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 3, 11, 4, 0, 0, 0, jakarta),
+		},
+		{
+			name:      "jam server UTC, hari bisnis tetap di timezone bisnis",
+			days:      businessDay{loc: jakarta},
+			now:       time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC), // 01:00 WIB tgl 11
+			wantStart: time.Date(2025, 3, 11, 0, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 3, 12, 0, 0, 0, 0, jakarta),
+		},
+		{
+			name:      "?tz= override timezone config",
+			days:      businessDay{loc: jakarta},
+			override:  makassar,
+			now:       time.Date(2025, 3, 10, 16, 30, 0, 0, time.UTC), // 23:30 WIB, 00:30 WITA
+			wantStart: time.Date(2025, 3, 11, 0, 0, 0, 0, makassar),
+			wantEnd:   time.Date(2025, 3, 12, 0, 0, 0, 0, makassar),
+		},
+		{
+			name:      "akhir bulan",
+			days:      businessDay{loc: jakarta, cutoff: 4 * time.Hour},
+			now:       time.Date(2025, 3, 1, 3, 0, 0, 0, jakarta),
+			wantStart: time.Date(2025, 2, 28, 4, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 3, 1, 4, 0, 0, 0, jakarta),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := model.ReportFilter{Location: tt.override}
+			tt.days.todayAt(&f, tt.now)
+
+			if !f.Start.Equal(tt.wantStart) || !f.End.Equal(tt.wantEnd) {
+				t.Errorf("range = %v .. %v, want %v .. %v", f.Start, f.End, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestBusinessDayDateRange(t *testing.T) {
+	jakarta := mustLoad(t, "Asia/Jakarta")
+	makassar := mustLoad(t, "Asia/Makassar")
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		days       businessDay
+		override   *time.Location
+		start, end time.Time // dari handler: 00:00 UTC, end sudah +1 hari (exclusive)
+		wantStart  time.Time
+		wantEnd    time.Time
+	}{
+		{
+			name:      "satu hari, end exclusive",
+			days:      businessDay{loc: jakarta},
+			start:     date(2025, 3, 10),
+			end:       date(2025, 3, 11),
+			wantStart: time.Date(2025, 3, 10, 0, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 3, 11, 0, 0, 0, 0, jakarta),
+		},
+		{
+			name:      "cutoff menggeser kedua batas",
+			days:      businessDay{loc: jakarta, cutoff: 4 * time.Hour},
+			start:     date(2025, 3, 1),
+			end:       date(2025, 4, 1),
+			wantStart: time.Date(2025, 3, 1, 4, 0, 0, 0, jakarta),
+			wantEnd:   time.Date(2025, 4, 1, 4, 0, 0, 0, jakarta),
+		},
+		{
+			name:      "?tz= override, cutoff tetap dari config",
+			days:      businessDay{loc: jakarta, cutoff: 30 * time.Minute},
+			override:  makassar,
+			start:     date(2025, 3, 10),
+			end:       date(2025, 3, 12),
+			wantStart: time.Date(2025, 3, 10, 0, 30, 0, 0, makassar),
+			wantEnd:   time.Date(2025, 3, 12, 0, 30, 0, 0, makassar),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := model.ReportFilter{Start: tt.start, End: tt.end, Location: tt.override}
+			tt.days.dateRange(&f)
+
+			if !f.Start.Equal(tt.wantStart) || !f.End.Equal(tt.wantEnd) {
+				t.Errorf("range = %v .. %v, want %v .. %v", f.Start, f.End, tt.wantStart, tt.wantEnd)
+			}
+			if f.DayCutoff != tt.days.cutoff {
+				t.Errorf("DayCutoff = %v, want %v", f.DayCutoff, tt.days.cutoff)
+			}
+		})
+	}
+}
+
+// DST: cutoff dihitung sebagai jam dinding, bukan durasi dari tengah malam
+func TestDayStartDST(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+
+	// 2025-03-09: jam 02:00 lompat ke 03:00
+	got := dayStart(time.Date(2025, 3, 9, 12, 0, 0, 0, ny), ny, 4*time.Hour)
+	if want := time.Date(2025, 3, 9, 4, 0, 0, 0, ny); !got.Equal(want) || got.Hour() != 4 {
+		t.Errorf("dayStart = %v, want %v", got, want)
+	}
+}
@@ -2,43 +2,138 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackyansen22/crud-category/internal/model"
 	"github.com/jackyansen22/crud-category/internal/repository"
 )
 
 type CategoryService interface {
-	GetAll(ctx context.Context) ([]model.Category, error)
+	GetAll(ctx context.Context, page model.PageRequest, includeDeleted bool) (*model.Page[model.Category], error)
 	GetByID(ctx context.Context, id int) (*model.Category, error)
+	GetTree(ctx context.Context, rootID *int) ([]model.Category, error)
+	GetAncestors(ctx context.Context, id int) ([]model.Category, error)
 	Create(ctx context.Context, c *model.Category) error
 	Update(ctx context.Context, c *model.Category) error
-	Delete(ctx context.Context, id int) error
+	Move(ctx context.Context, id int, parentID *int) error
+	Delete(ctx context.Context, id int, req model.CategoryDeleteRequest) error
+	Restore(ctx context.Context, id int) (*model.Category, error)
+	PurgeDeleted(ctx context.Context) (int64, error)
 }
 
 type categoryService struct {
 	repo repository.CategoryRepository
+
+	// category soft delete di-purge setelah retention ini
+	retention time.Duration
 }
 
-func NewCategoryService(repo repository.CategoryRepository) CategoryService {
-	return &categoryService{repo: repo}
+func NewCategoryService(repo repository.CategoryRepository, retention time.Duration) CategoryService {
+	return &categoryService{repo: repo, retention: retention}
 }
 
-func (s *categoryService) GetAll(ctx context.Context) ([]model.Category, error) {
-	return s.repo.FindAll(ctx)
+func (s *categoryService) GetAll(
+	ctx context.Context,
+	page model.PageRequest,
+	includeDeleted bool,
+) (*model.Page[model.Category], error) {
+	return s.repo.FindAll(ctx, page, includeDeleted)
 }
 
 func (s *categoryService) GetByID(ctx context.Context, id int) (*model.Category, error) {
 	return s.repo.FindByID(ctx, id)
 }
 
+// =====================================================
+// TREE
+// - rootID nil  → seluruh forest (semua root category)
+// - rootID != nil → hanya subtree dari category tsb
+// =====================================================
+func (s *categoryService) GetTree(ctx context.Context, rootID *int) ([]model.Category, error) {
+	var (
+		flat []model.Category
+		err  error
+	)
+
+	if rootID != nil {
+		flat, err = s.repo.FindSubtree(ctx, *rootID)
+	} else {
+		// tree selalu utuh, tanpa limit
+		var all *model.Page[model.Category]
+		all, err = s.repo.FindAll(ctx, model.PageRequest{}, false)
+		if all != nil {
+			flat = all.Data
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCategoryTree(flat, rootID), nil
+}
+
+func (s *categoryService) GetAncestors(ctx context.Context, id int) ([]model.Category, error) {
+	return s.repo.FindAncestors(ctx, id)
+}
+
 func (s *categoryService) Create(ctx context.Context, c *model.Category) error {
 	return s.repo.Create(ctx, c)
 }
 
+// Update juga memindahkan node kalau parent_id berubah;
+// cycle dicek di repository dalam satu sql.Tx.
 func (s *categoryService) Update(ctx context.Context, c *model.Category) error {
 	return s.repo.Update(ctx, c)
 }
 
-func (s *categoryService) Delete(ctx context.Context, id int) error {
-	return s.repo.Delete(ctx, id)
+func (s *categoryService) Move(ctx context.Context, id int, parentID *int) error {
+	return s.repo.Move(ctx, id, parentID)
+}
+
+func (s *categoryService) Delete(ctx context.Context, id int, req model.CategoryDeleteRequest) error {
+	return s.repo.Delete(ctx, id, req)
+}
+
+func (s *categoryService) Restore(ctx context.Context, id int) (*model.Category, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, id)
+}
+
+// jalankan setelah ProductService.PurgeDeleted
+// (category baru bisa di-purge kalau produknya sudah hilang)
+func (s *categoryService) PurgeDeleted(ctx context.Context) (int64, error) {
+	return s.repo.PurgeDeleted(ctx, time.Now().Add(-s.retention))
+}
+
+func buildCategoryTree(flat []model.Category, rootID *int) []model.Category {
+	children := make(map[int][]model.Category)
+	var roots []model.Category
+
+	for _, c := range flat {
+		switch {
+		case rootID != nil && c.ID == *rootID:
+			roots = append(roots, c)
+		case rootID == nil && c.ParentID == nil:
+			roots = append(roots, c)
+		case c.ParentID != nil:
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		}
+	}
+
+	var attach func(c model.Category) model.Category
+	attach = func(c model.Category) model.Category {
+		for _, child := range children[c.ID] {
+			c.Children = append(c.Children, attach(child))
+		}
+		return c
+	}
+
+	tree := make([]model.Category, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, attach(root))
+	}
+
+	return tree
 }
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/repository"
+)
+
+type OutletService interface {
+	GetAll(ctx context.Context) ([]model.Outlet, error)
+	GetByID(ctx context.Context, id int) (*model.Outlet, error)
+	GetStock(ctx context.Context, id int) ([]model.OutletStock, error)
+	Create(ctx context.Context, o *model.Outlet) error
+	Update(ctx context.Context, o *model.Outlet) error
+}
+
+type outletService struct {
+	repo repository.OutletRepository
+}
+
+func NewOutletService(repo repository.OutletRepository) OutletService {
+	return &outletService{repo: repo}
+}
+
+func (s *outletService) GetAll(ctx context.Context) ([]model.Outlet, error) {
+	return s.repo.FindAll(ctx)
+}
+
+func (s *outletService) GetByID(ctx context.Context, id int) (*model.Outlet, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *outletService) GetStock(ctx context.Context, id int) ([]model.OutletStock, error) {
+	return s.repo.FindStock(ctx, id)
+}
+
+func (s *outletService) Create(ctx context.Context, o *model.Outlet) error {
+	// default active, outlet default hanya lewat update
+	o.Active = true
+	o.IsDefault = false
+
+	return s.repo.Create(ctx, o)
+}
+
+func (s *outletService) Update(ctx context.Context, o *model.Outlet) error {
+	return s.repo.Update(ctx, o)
+}
@@ -1,40 +1,73 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/jackyansen22/crud-category/internal/apperror"
 	"github.com/jackyansen22/crud-category/internal/model"
 	"github.com/jackyansen22/crud-category/internal/repository"
+	"github.com/jackyansen22/crud-category/internal/validation"
+)
+
+var (
+	ErrCategoryRequired = apperror.Validation("CATEGORY_REQUIRED", "category_id is required")
+	ErrInvalidBarcode   = apperror.Validation("INVALID_BARCODE", "barcode must be a valid EAN-13 or UPC-A")
+
+	ErrEmptyImport        = apperror.Validation("EMPTY_IMPORT", "import file has no rows")
+	ErrImportTooLarge     = apperror.Validation("IMPORT_TOO_LARGE", fmt.Sprintf("import file may contain at most %d rows", validation.MaxImportRows))
+	ErrInvalidImportRow   = apperror.Validation("INVALID_IMPORT_ROW", "row cannot be parsed")
+	ErrDuplicateImportRow = apperror.Validation("DUPLICATE_IMPORT_ROW", "product appears more than once in the file")
+	ErrImportFailed       = apperror.Unprocessable("IMPORT_FAILED", "import has invalid rows, nothing was saved")
+
+	ErrBulkSelectionRequired = apperror.Validation("BULK_SELECTION_REQUIRED", "ids or a filter is required")
 )
 
 type ProductService interface {
-	GetAll(ctx context.Context) ([]model.Product, error)
-	Search(ctx context.Context, name string, active *bool) ([]model.Product, error)
+	Search(ctx context.Context, f model.ProductFilter, page model.PageRequest) (*model.Page[model.Product], error)
+	Export(ctx context.Context, f model.ProductFilter, fn func(model.Product) error) error
 	GetByID(ctx context.Context, id int) (*model.Product, error)
+	GetByBarcode(ctx context.Context, code string) (*model.Product, error)
+	GetCostHistory(ctx context.Context, id int) ([]model.ProductCost, error)
 	Create(ctx context.Context, p *model.Product) error
 	Update(ctx context.Context, p *model.Product) error
-	Delete(ctx context.Context, id int) error
+	Import(ctx context.Context, rows []model.ProductImportRow, dryRun bool) (*model.ProductImportResult, error)
+	BulkUpdate(ctx context.Context, u model.ProductBulkUpdate) (*model.ProductBulkResult, error)
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) (*model.Product, error)
+	PurgeDeleted(ctx context.Context) (int64, error)
 }
 
 func (s *productService) Search(
 	ctx context.Context,
-	name string,
-	active *bool,
-) ([]model.Product, error) {
-	return s.repo.FindByFilter(ctx, name, active)
+	f model.ProductFilter,
+	page model.PageRequest,
+) (*model.Page[model.Product], error) {
+	return s.repo.FindByFilter(ctx, f, page)
+}
+
+func (s *productService) Export(
+	ctx context.Context,
+	f model.ProductFilter,
+	fn func(model.Product) error,
+) error {
+	return s.repo.ExportByFilter(ctx, f, fn)
 }
 
 type productService struct {
 	repo repository.ProductRepository
-}
 
-func NewProductService(repo repository.ProductRepository) ProductService {
-	return &productService{repo: repo}
+	// produk soft delete di-purge setelah retention ini
+	retention time.Duration
 }
 
-func (s *productService) GetAll(ctx context.Context) ([]model.Product, error) {
-	return s.repo.FindAll(ctx)
+func NewProductService(repo repository.ProductRepository, retention time.Duration) ProductService {
+	return &productService{repo: repo, retention: retention}
 }
 
 func (s *productService) GetByID(ctx context.Context, id int) (*model.Product, error) {
@@ -50,21 +83,184 @@ func (s *productService) Create(
 	p.Active = true
 
 	if p.CategoryID == 0 {
-		return errors.New("category_id is required")
+		return ErrCategoryRequired
 	}
 
 	// ✅ VALIDASI FK DI SERVICE
 	if !s.repo.CategoryExists(ctx, p.CategoryID) {
-		return errors.New("category not found")
+		return repository.ErrInvalidCategory
+	}
+
+	if err := normalizeBarcodes(p.Barcodes); err != nil {
+		return err
 	}
 
 	return s.repo.Create(ctx, p)
 }
 
+// category_id ikut diupdate, dicek di repository (di dalam tx)
 func (s *productService) Update(ctx context.Context, p *model.Product) error {
-	return s.repo.Update(ctx, p)
+	if err := normalizeBarcodes(p.Barcodes); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		return err
+	}
+
+	// response lengkap (barcodes, category_name, updated_at)
+	updated, err := s.repo.FindByID(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	*p = *updated
+	return nil
+}
+
+// =====================================================
+// IMPORT
+// - baris divalidasi dulu, baris valid dicek ke database
+// - ada error di run sungguhan → tidak ada yang disimpan (IMPORT_FAILED)
+// - dry run → selalu rollback, error per baris ada di result
+// =====================================================
+func (s *productService) Import(
+	ctx context.Context,
+	rows []model.ProductImportRow,
+	dryRun bool,
+) (*model.ProductImportResult, error) {
+
+	if len(rows) == 0 {
+		return nil, ErrEmptyImport
+	}
+	if len(rows) > validation.MaxImportRows {
+		return nil, ErrImportTooLarge
+	}
+
+	var (
+		valid []model.ProductImportRow
+		errs  []model.ProductImportError
+		seen  = make(map[string]int) // key → baris pertama
+	)
+	for _, row := range rows {
+		if err := importRowError(&row, seen); err != nil {
+			errs = append(errs, *err)
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	// baris invalid → sisanya tetap dicek tapi tidak disimpan
+	result, err := s.repo.Import(ctx, valid, dryRun || len(errs) > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result.DryRun = dryRun
+	result.Total = len(rows)
+	result.Errors = append(result.Errors, errs...)
+	result.Failed = len(result.Errors)
+	slices.SortStableFunc(result.Errors, func(a, b model.ProductImportError) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+
+	if !dryRun && result.Failed > 0 {
+		return nil, ErrImportFailed.WithDetails(result.Errors)
+	}
+	return result, nil
+}
+
+// =====================================================
+// BULK UPDATE
+// seleksi kosong ditolak supaya tidak mengubah semua produk tanpa sengaja
+// =====================================================
+func (s *productService) BulkUpdate(
+	ctx context.Context,
+	u model.ProductBulkUpdate,
+) (*model.ProductBulkResult, error) {
+
+	if len(u.IDs) == 0 && u.Filter == nil {
+		return nil, ErrBulkSelectionRequired
+	}
+
+	if u.CategoryID != nil && !s.repo.CategoryExists(ctx, *u.CategoryID) {
+		return nil, repository.ErrInvalidCategory
+	}
+
+	return s.repo.BulkUpdate(ctx, u)
+}
+
+func importRowError(row *model.ProductImportRow, seen map[string]int) *model.ProductImportError {
+	rowErr := func(err error) *model.ProductImportError {
+		appErr := ErrInvalidImportRow.WithMessage(err.Error())
+		errors.As(err, &appErr)
+		return &model.ProductImportError{Line: row.Line, Code: appErr.Code, Message: appErr.Message, Details: appErr.Details}
+	}
+
+	if row.ParseError != "" {
+		return rowErr(ErrInvalidImportRow.WithMessage(row.ParseError))
+	}
+
+	row.SKU = strings.TrimSpace(row.SKU)
+	row.Nama = strings.TrimSpace(row.Nama)
+	row.Kategori = strings.TrimSpace(row.Kategori)
+
+	if err := validation.ProductImport(row); err != nil {
+		return rowErr(err)
+	}
+	if err := normalizeBarcodes(row.Barcodes); err != nil {
+		return rowErr(err)
+	}
+
+	// produk yang sama dua kali di satu file
+	key := "nama:" + strings.ToLower(row.Nama)
+	if row.SKU != "" {
+		key = "sku:" + row.SKU
+	}
+	if first, ok := seen[key]; ok {
+		return rowErr(ErrDuplicateImportRow.WithDetails(map[string]int{"first_line": first}))
+	}
+	seen[key] = row.Line
+
+	return nil
+}
+
+// code boleh EAN-13 atau UPC-A, dicari sebagai GTIN-13
+func (s *productService) GetByBarcode(ctx context.Context, code string) (*model.Product, error) {
+	normalized, ok := validation.NormalizeBarcode(code)
+	if !ok {
+		return nil, ErrInvalidBarcode
+	}
+	return s.repo.FindByBarcode(ctx, normalized)
+}
+
+// UPC-A → GTIN-13 (in place)
+func normalizeBarcodes(codes []string) error {
+	for i, code := range codes {
+		normalized, ok := validation.NormalizeBarcode(code)
+		if !ok {
+			return ErrInvalidBarcode.WithDetails(map[string]string{"barcode": code})
+		}
+		codes[i] = normalized
+	}
+	return nil
+}
+
+// version dari If-Match, 0 = tidak dicek
+func (s *productService) Delete(ctx context.Context, id int, version int) error {
+	return s.repo.Delete(ctx, id, version)
+}
+
+func (s *productService) Restore(ctx context.Context, id int) (*model.Product, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *productService) GetCostHistory(ctx context.Context, id int) ([]model.ProductCost, error) {
+	return s.repo.FindCostHistory(ctx, id)
 }
 
-func (s *productService) Delete(ctx context.Context, id int) error {
-	return s.repo.Delete(ctx, id)
+func (s *productService) PurgeDeleted(ctx context.Context) (int64, error) {
+	return s.repo.PurgeDeleted(ctx, time.Now().Add(-s.retention))
 }
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/repository"
+)
+
+type PurchaseOrderService interface {
+	GetAll(ctx context.Context, f model.PurchaseOrderFilter, page model.PageRequest) (*model.Page[model.PurchaseOrder], error)
+	GetByID(ctx context.Context, id int) (*model.PurchaseOrder, error)
+	Create(ctx context.Context, po *model.PurchaseOrder) error
+	Update(ctx context.Context, po *model.PurchaseOrder) (*model.PurchaseOrder, error)
+	Order(ctx context.Context, id int) (*model.PurchaseOrder, error)
+	Cancel(ctx context.Context, id int) (*model.PurchaseOrder, error)
+	Receive(ctx context.Context, id int, req model.ReceiveRequest) (*model.PurchaseOrder, error)
+}
+
+type purchaseOrderService struct {
+	repo repository.PurchaseOrderRepository
+}
+
+func NewPurchaseOrderService(repo repository.PurchaseOrderRepository) PurchaseOrderService {
+	return &purchaseOrderService{repo: repo}
+}
+
+func (s *purchaseOrderService) GetAll(
+	ctx context.Context,
+	f model.PurchaseOrderFilter,
+	page model.PageRequest,
+) (*model.Page[model.PurchaseOrder], error) {
+	return s.repo.FindAll(ctx, f, page)
+}
+
+func (s *purchaseOrderService) GetByID(ctx context.Context, id int) (*model.PurchaseOrder, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *purchaseOrderService) Create(ctx context.Context, po *model.PurchaseOrder) error {
+	return s.repo.Create(ctx, po)
+}
+
+// response diambil ulang supaya timestamp & nama produk terisi
+func (s *purchaseOrderService) Update(ctx context.Context, po *model.PurchaseOrder) (*model.PurchaseOrder, error) {
+	if err := s.repo.Update(ctx, po); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, po.ID)
+}
+
+func (s *purchaseOrderService) Order(ctx context.Context, id int) (*model.PurchaseOrder, error) {
+	if err := s.repo.Order(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *purchaseOrderService) Cancel(ctx context.Context, id int) (*model.PurchaseOrder, error) {
+	if err := s.repo.Cancel(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *purchaseOrderService) Receive(
+	ctx context.Context,
+	id int,
+	req model.ReceiveRequest,
+) (*model.PurchaseOrder, error) {
+
+	if err := s.repo.Receive(ctx, id, req); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, id)
+}
@@ -9,29 +9,34 @@ import (
 )
 
 type ReportService interface {
-	GetToday(ctx context.Context) (*model.ReportResponse, error)
-	GetByRange(ctx context.Context, start, end time.Time) (*model.ReportResponse, error)
+	GetToday(ctx context.Context, f model.ReportFilter) (*model.ReportResponse, error)
+	GetByRange(ctx context.Context, f model.ReportFilter) (*model.ReportResponse, error)
 }
 
 type reportService struct {
 	repo repository.ReportRepository
+	days businessDay
 }
 
-func NewReportService(repo repository.ReportRepository) ReportService {
-	return &reportService{repo: repo}
+func NewReportService(
+	repo repository.ReportRepository,
+	loc *time.Location,
+	cutoff time.Duration,
+) ReportService {
+	return &reportService{repo: repo, days: businessDay{loc: loc, cutoff: cutoff}}
 }
 
-func (s *reportService) GetToday(ctx context.Context) (*model.ReportResponse, error) {
-	now := time.Now()
-	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	end := start.Add(24 * time.Hour)
-
-	return s.repo.GetReport(ctx, start, end)
+// Start / End diisi dengan hari bisnis yang sedang berjalan
+func (s *reportService) GetToday(ctx context.Context, f model.ReportFilter) (*model.ReportResponse, error) {
+	s.days.today(&f)
+	return s.repo.GetReport(ctx, f)
 }
 
+// Start / End dari handler = tanggal, diubah ke batas hari bisnis
 func (s *reportService) GetByRange(
 	ctx context.Context,
-	start, end time.Time,
+	f model.ReportFilter,
 ) (*model.ReportResponse, error) {
-	return s.repo.GetReport(ctx, start, end)
+	s.days.dateRange(&f)
+	return s.repo.GetReport(ctx, f)
 }
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/repository"
+)
+
+var ErrInvalidStockMovement = apperror.Validation("INVALID_STOCK_MOVEMENT", "invalid stock movement")
+
+type StockService interface {
+	Adjust(ctx context.Context, productID int, req model.StockAdjustmentRequest) (*model.StockMovement, error)
+	GetHistory(ctx context.Context, productID int) ([]model.StockMovement, error)
+	CheckConsistency(ctx context.Context) ([]model.StockDrift, error)
+	Rebuild(ctx context.Context) ([]model.StockDrift, error)
+}
+
+type stockService struct {
+	repo repository.StockRepository
+}
+
+func NewStockService(repo repository.StockRepository) StockService {
+	return &stockService{repo: repo}
+}
+
+// =====================================================
+// MANUAL MOVEMENT
+// - sale & refund hanya lewat checkout / refund
+// =====================================================
+func (s *stockService) Adjust(
+	ctx context.Context,
+	productID int,
+	req model.StockAdjustmentRequest,
+) (*model.StockMovement, error) {
+
+	switch req.Type {
+	case model.StockMovementAdjustment:
+		if req.Quantity == 0 {
+			return nil, fmt.Errorf("%w: adjustment quantity cannot be zero", ErrInvalidStockMovement)
+		}
+		if req.Reason == "" {
+			return nil, fmt.Errorf("%w: reason is required for adjustment", ErrInvalidStockMovement)
+		}
+	case model.StockMovementReceiving:
+		if req.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: receiving quantity must be greater than zero", ErrInvalidStockMovement)
+		}
+	case model.StockMovementStocktake:
+		if req.Quantity < 0 {
+			return nil, fmt.Errorf("%w: stocktake quantity cannot be negative", ErrInvalidStockMovement)
+		}
+	default:
+		return nil, fmt.Errorf("%w: invalid movement type (adjustment/receiving/stocktake)", ErrInvalidStockMovement)
+	}
+
+	return s.repo.Adjust(ctx, productID, req)
+}
+
+func (s *stockService) GetHistory(ctx context.Context, productID int) ([]model.StockMovement, error) {
+	return s.repo.FindByProduct(ctx, productID)
+}
+
+func (s *stockService) CheckConsistency(ctx context.Context) ([]model.StockDrift, error) {
+	return s.repo.CheckConsistency(ctx)
+}
+
+func (s *stockService) Rebuild(ctx context.Context) ([]model.StockDrift, error) {
+	return s.repo.Rebuild(ctx)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/repository"
+)
+
+type SupplierService interface {
+	GetAll(ctx context.Context) ([]model.Supplier, error)
+	GetByID(ctx context.Context, id int) (*model.Supplier, error)
+	Create(ctx context.Context, s *model.Supplier) error
+	Update(ctx context.Context, s *model.Supplier) error
+}
+
+type supplierService struct {
+	repo repository.SupplierRepository
+}
+
+func NewSupplierService(repo repository.SupplierRepository) SupplierService {
+	return &supplierService{repo: repo}
+}
+
+func (s *supplierService) GetAll(ctx context.Context) ([]model.Supplier, error) {
+	return s.repo.FindAll(ctx)
+}
+
+func (s *supplierService) GetByID(ctx context.Context, id int) (*model.Supplier, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *supplierService) Create(ctx context.Context, sp *model.Supplier) error {
+	// default active
+	sp.Active = true
+
+	return s.repo.Create(ctx, sp)
+}
+
+func (s *supplierService) Update(ctx context.Context, sp *model.Supplier) error {
+	return s.repo.Update(ctx, sp)
+}
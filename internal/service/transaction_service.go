@@ -2,49 +2,164 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/jackyansen22/crud-category/internal/apperror"
 	"github.com/jackyansen22/crud-category/internal/model"
 	"github.com/jackyansen22/crud-category/internal/repository"
+	"github.com/jackyansen22/crud-category/internal/validation"
+)
+
+var (
+	ErrEmptyCheckout       = apperror.Validation("EMPTY_CHECKOUT", "checkout items cannot be empty")
+	ErrInvalidPayment      = apperror.Validation("INVALID_PAYMENT", "invalid payment")
+	ErrIdempotencyMismatch = apperror.Conflict("IDEMPOTENCY_KEY_MISMATCH", "idempotency key was already used with a different request body")
 )
 
 type TransactionService interface {
-	Checkout(ctx context.Context, items []model.CheckoutItem) (*model.Transaction, error)
+	Checkout(ctx context.Context, req *model.CheckoutRequest) (*model.Transaction, error)
 
-	GetAll(ctx context.Context) ([]model.Transaction, error)
+	GetAll(ctx context.Context, page model.PageRequest) (*model.Page[model.Transaction], error)
 	GetByID(ctx context.Context, id int) (*model.Transaction, error)
+	Export(ctx context.Context, f model.ReportFilter, fn func(model.TransactionExportLine) error) error
+
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+
+	Refund(ctx context.Context, id int, req model.RefundRequest) (*model.Refund, error)
+	Void(ctx context.Context, id int, reason string) (*model.Refund, error)
 }
 
 type transactionService struct {
-	repo repository.TransactionRepository
+	repo           repository.TransactionRepository
+	idempotencyTTL time.Duration
+	days           businessDay
 }
 
-func NewTransactionService(repo repository.TransactionRepository) TransactionService {
-	return &transactionService{repo: repo}
+func NewTransactionService(
+	repo repository.TransactionRepository,
+	idempotencyTTL time.Duration,
+	loc *time.Location,
+	cutoff time.Duration,
+) TransactionService {
+	return &transactionService{
+		repo:           repo,
+		idempotencyTTL: idempotencyTTL,
+		days:           businessDay{loc: loc, cutoff: cutoff},
+	}
 }
 
 // =====================================================
 // CHECKOUT
 // - atomic transaction
 // - calculate subtotal & total
+// - payments: cover total, kembalian dari cash
+// - Idempotency-Key: retry dengan key sama → transaksi lama
 // =====================================================
 func (s *transactionService) Checkout(
 	ctx context.Context,
-	items []model.CheckoutItem,
+	req *model.CheckoutRequest,
 ) (*model.Transaction, error) {
 
-	if len(items) == 0 {
-		return nil, errors.New("checkout items cannot be empty")
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyCheckout
+	}
+
+	for i, item := range req.Items {
+		if item.Barcode == "" {
+			continue
+		}
+		normalized, ok := validation.NormalizeBarcode(item.Barcode)
+		if !ok {
+			return nil, ErrInvalidBarcode.WithDetails(map[string]string{"barcode": item.Barcode})
+		}
+		req.Items[i].Barcode = normalized
+	}
+
+	for _, p := range req.Payments {
+		if !model.IsValidPaymentMethod(p.Method) {
+			return nil, fmt.Errorf("%w: method %q (cash/card/qris/ewallet)", ErrInvalidPayment, p.Method)
+		}
+		if p.Amount <= 0 {
+			return nil, fmt.Errorf("%w: amount must be greater than zero", ErrInvalidPayment)
+		}
+	}
+
+	if req.Idempotency != nil {
+		hash, err := checkoutRequestHash(req)
+		if err != nil {
+			return nil, err
+		}
+		req.Idempotency.RequestHash = hash
+		req.Idempotency.ExpiresAt = time.Now().Add(s.idempotencyTTL)
+
+		if t, err := s.replay(ctx, req.Idempotency); t != nil || err != nil {
+			return t, err
+		}
 	}
 
 	// Business orchestration delegated to repository (sql.Tx)
-	return s.repo.CreateTransaction(ctx, items)
+	t, err := s.repo.CreateTransaction(ctx, req)
+
+	// checkout paralel dengan key sama sudah commit duluan
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		if t, err := s.replay(ctx, req.Idempotency); t != nil || err != nil {
+			return t, err
+		}
+	}
+
+	return t, err
+}
+
+// transaksi lama untuk key ini, nil kalau key belum pernah dipakai
+func (s *transactionService) replay(
+	ctx context.Context,
+	k *model.IdempotencyKey,
+) (*model.Transaction, error) {
+
+	stored, err := s.repo.FindIdempotencyKey(ctx, k.Key)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+
+	if stored.RequestHash != k.RequestHash {
+		return nil, ErrIdempotencyMismatch
+	}
+
+	t, err := s.repo.FindByID(ctx, stored.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	t.Replayed = true
+
+	return t, nil
+}
+
+func checkoutRequestHash(req *model.CheckoutRequest) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *transactionService) PurgeExpiredIdempotencyKeys(
+	ctx context.Context,
+) (int64, error) {
+	return s.repo.DeleteExpiredIdempotencyKeys(ctx)
 }
 
 func (s *transactionService) GetAll(
 	ctx context.Context,
-) ([]model.Transaction, error) {
-	return s.repo.FindAll(ctx)
+	page model.PageRequest,
+) (*model.Page[model.Transaction], error) {
+	return s.repo.FindAll(ctx, page)
 }
 
 func (s *transactionService) GetByID(
@@ -53,3 +168,45 @@ func (s *transactionService) GetByID(
 ) (*model.Transaction, error) {
 	return s.repo.FindByID(ctx, id)
 }
+
+// =====================================================
+// EXPORT
+// - Start / End dari handler = tanggal (zero = semua transaksi)
+// - created_at dikonversi ke timezone bisnis
+// =====================================================
+func (s *transactionService) Export(
+	ctx context.Context,
+	f model.ReportFilter,
+	fn func(model.TransactionExportLine) error,
+) error {
+	if f.Start.IsZero() {
+		s.days.apply(&f)
+	} else {
+		s.days.dateRange(&f)
+	}
+
+	return s.repo.ExportLines(ctx, f, func(l model.TransactionExportLine) error {
+		l.CreatedAt = l.CreatedAt.In(f.Location)
+		return fn(l)
+	})
+}
+
+// =====================================================
+// REFUND / VOID
+// - stok dikembalikan & refund dicatat dalam satu sql.Tx
+// =====================================================
+func (s *transactionService) Refund(
+	ctx context.Context,
+	id int,
+	req model.RefundRequest,
+) (*model.Refund, error) {
+	return s.repo.Refund(ctx, id, req)
+}
+
+func (s *transactionService) Void(
+	ctx context.Context,
+	id int,
+	reason string,
+) (*model.Refund, error) {
+	return s.repo.Void(ctx, id, reason)
+}
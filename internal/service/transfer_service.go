@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/repository"
+)
+
+type TransferService interface {
+	GetAll(ctx context.Context, status string, page model.PageRequest) (*model.Page[model.StockTransfer], error)
+	GetByID(ctx context.Context, id int) (*model.StockTransfer, error)
+	Create(ctx context.Context, t *model.StockTransfer) error
+	Receive(ctx context.Context, id int) (*model.StockTransfer, error)
+	Cancel(ctx context.Context, id int) (*model.StockTransfer, error)
+}
+
+type transferService struct {
+	repo repository.TransferRepository
+}
+
+func NewTransferService(repo repository.TransferRepository) TransferService {
+	return &transferService{repo: repo}
+}
+
+func (s *transferService) GetAll(
+	ctx context.Context,
+	status string,
+	page model.PageRequest,
+) (*model.Page[model.StockTransfer], error) {
+	return s.repo.FindAll(ctx, status, page)
+}
+
+func (s *transferService) GetByID(ctx context.Context, id int) (*model.StockTransfer, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *transferService) Create(ctx context.Context, t *model.StockTransfer) error {
+	return s.repo.Create(ctx, t)
+}
+
+func (s *transferService) Receive(ctx context.Context, id int) (*model.StockTransfer, error) {
+	if err := s.repo.Receive(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *transferService) Cancel(ctx context.Context, id int) (*model.StockTransfer, error) {
+	if err := s.repo.Cancel(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, id)
+}
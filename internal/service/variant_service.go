@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+	"github.com/jackyansen22/crud-category/internal/repository"
+)
+
+type VariantService interface {
+	GetByProduct(ctx context.Context, productID int) ([]model.ProductVariant, error)
+	GetByID(ctx context.Context, productID, variantID int) (*model.ProductVariant, error)
+	Create(ctx context.Context, v *model.ProductVariant) error
+	Update(ctx context.Context, v *model.ProductVariant) error
+	Delete(ctx context.Context, productID, variantID int) error
+}
+
+type variantService struct {
+	repo repository.VariantRepository
+}
+
+func NewVariantService(repo repository.VariantRepository) VariantService {
+	return &variantService{repo: repo}
+}
+
+func (s *variantService) GetByProduct(ctx context.Context, productID int) ([]model.ProductVariant, error) {
+	return s.repo.FindByProduct(ctx, productID)
+}
+
+func (s *variantService) GetByID(ctx context.Context, productID, variantID int) (*model.ProductVariant, error) {
+	return s.repo.FindByID(ctx, productID, variantID)
+}
+
+func (s *variantService) Create(ctx context.Context, v *model.ProductVariant) error {
+	// default active
+	v.Active = true
+
+	return s.repo.Create(ctx, v)
+}
+
+func (s *variantService) Update(ctx context.Context, v *model.ProductVariant) error {
+	return s.repo.Update(ctx, v)
+}
+
+func (s *variantService) Delete(ctx context.Context, productID, variantID int) error {
+	return s.repo.Delete(ctx, productID, variantID)
+}
@@ -0,0 +1,40 @@
+package validation
+
+// =====================================================
+// BARCODE (EAN-13 / UPC-A)
+// UPC-A (12 digit) = EAN-13 dengan leading 0, jadi keduanya
+// dinormalisasi ke GTIN-13 supaya scan dari scanner manapun cocok
+// =====================================================
+func NormalizeBarcode(code string) (string, bool) {
+	switch len(code) {
+	case 12:
+		code = "0" + code
+	case 13:
+	default:
+		return "", false
+	}
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+
+	if gtinCheckDigit(code[:12]) != code[12] {
+		return "", false
+	}
+	return code, true
+}
+
+// check digit GTIN: dari kanan, bobot 3 dan 1 bergantian
+func gtinCheckDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
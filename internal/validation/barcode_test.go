@@ -0,0 +1,54 @@
+package validation
+
+import "testing"
+
+func TestNormalizeBarcode(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		want   string
+		wantOK bool
+	}{
+		{"EAN-13 valid", "4006381333931", "4006381333931", true},
+		{"EAN-13 valid check digit 7", "5901234123457", "5901234123457", true},
+		{"EAN-13 check digit 0", "4006381333900", "4006381333900", true},
+		{"EAN-13 check digit salah", "4006381333932", "", false},
+		{"UPC-A dipad ke GTIN-13", "036000291452", "0036000291452", true},
+		{"UPC-A check digit salah", "036000291453", "", false},
+		{"bukan digit", "400638133393A", "", false},
+		{"spasi", "4006381 33931", "", false},
+		{"tanda minus", "-36000291452", "", false},
+		{"kosong", "", "", false},
+		{"11 digit", "03600029145", "", false},
+		{"14 digit (GTIN-14)", "14006381333938", "", false},
+		{"EAN-8", "96385074", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeBarcode(tt.code)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("NormalizeBarcode(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGTINCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{"400638133393", '1'},
+		{"590123412345", '7'},
+		{"003600029145", '2'},
+		{"400638133390", '0'}, // sum kelipatan 10
+		{"000000000000", '0'},
+	}
+
+	for _, tt := range tests {
+		if got := gtinCheckDigit(tt.digits); got != tt.want {
+			t.Errorf("gtinCheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+		}
+	}
+}
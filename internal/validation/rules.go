@@ -0,0 +1,328 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/jackyansen22/crud-category/internal/model"
+)
+
+// batas payload (ikut ukuran kolom di migration 0001_init)
+const (
+	MaxNameLength        = 255
+	MaxDescriptionLength = 1000
+	MaxCheckoutItems     = 100
+	MaxCheckoutPayments  = 10
+	MaxItemQuantity      = 10000
+	MaxSKULength         = 64
+	MaxBarcodes          = 20
+	MaxVariantAttributes = 10
+	MaxAttributeLength   = 64
+	MaxTransferItems     = 100
+	MaxPurchaseItems     = 200
+	MaxPhoneLength       = 32
+	MaxImportRows        = 5000
+	MaxBulkIDs           = 1000
+)
+
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// =====================================================
+// CATEGORY (POST / PUT /categories)
+// =====================================================
+func Category(c *model.Category) error {
+	var v Validator
+
+	v.Required("name", c.Name)
+	v.MaxLength("name", c.Name, MaxNameLength)
+	v.MaxLength("description", c.Description, MaxDescriptionLength)
+
+	if c.ParentID != nil {
+		v.Min("parent_id", *c.ParentID, 1)
+		v.Check(c.ID == 0 || *c.ParentID != c.ID, "parent_id", "cannot be the category itself")
+	}
+
+	return v.Err()
+}
+
+// =====================================================
+// PRODUCT (POST / PUT /product)
+// =====================================================
+func Product(p *model.Product) error {
+	var v Validator
+
+	v.Required("nama", p.Nama)
+	v.MaxLength("nama", p.Nama, MaxNameLength)
+	v.Min("harga", p.Harga, 0)
+	if p.HargaPokok != nil {
+		v.Min("harga_pokok", *p.HargaPokok, 0)
+	}
+	v.Min("stok", p.Stok, 0)
+
+	// update tanpa category_id = kategori lama
+	if p.ID == 0 || p.CategoryID != 0 {
+		v.Min("category_id", p.CategoryID, 1)
+	}
+
+	sku(&v, "sku", p.SKU)
+	barcodes(&v, "barcodes", p.Barcodes)
+
+	return v.Err()
+}
+
+// =====================================================
+// PRODUCT IMPORT ROW (POST /product/import)
+// =====================================================
+func ProductImport(row *model.ProductImportRow) error {
+	var v Validator
+
+	v.Required("nama", row.Nama)
+	v.MaxLength("nama", row.Nama, MaxNameLength)
+	v.Required("kategori", row.Kategori)
+	v.MaxLength("kategori", row.Kategori, MaxNameLength)
+	v.Min("harga", row.Harga, 0)
+	if row.HargaPokok != nil {
+		v.Min("harga_pokok", *row.HargaPokok, 0)
+	}
+	if row.Stok != nil {
+		v.Min("stok", *row.Stok, 0)
+	}
+
+	sku(&v, "sku", row.SKU)
+	barcodes(&v, "barcodes", row.Barcodes)
+
+	return v.Err()
+}
+
+// =====================================================
+// PRODUCT BULK UPDATE (PATCH /product/bulk)
+// =====================================================
+func ProductBulkUpdate(u *model.ProductBulkUpdate) error {
+	var v Validator
+
+	v.Check(len(u.IDs) <= MaxBulkIDs, "ids", fmt.Sprintf("must contain at most %d ids", MaxBulkIDs))
+	for i, id := range u.IDs {
+		v.Min(fmt.Sprintf("ids[%d]", i), id, 1)
+	}
+
+	v.Check(u.Harga != nil || u.Active != nil || u.CategoryID != nil, "harga", "at least one of harga, active or category_id is required")
+
+	if u.Harga != nil {
+		ops := 0
+		if u.Harga.Set != nil {
+			ops++
+			v.Min("harga.set", *u.Harga.Set, 0)
+		}
+		if u.Harga.Add != nil {
+			ops++
+		}
+		if u.Harga.Percent != nil {
+			ops++
+			v.Check(*u.Harga.Percent >= -100 && *u.Harga.Percent <= 1000, "harga.percent", "must be between -100 and 1000")
+		}
+		v.Check(ops == 1, "harga", "exactly one of set, add or percent is required")
+	}
+
+	if u.CategoryID != nil {
+		v.Min("category_id", *u.CategoryID, 1)
+	}
+
+	return v.Err()
+}
+
+func barcodes(v *Validator, field string, codes []string) {
+	v.Check(len(codes) <= MaxBarcodes, field, fmt.Sprintf("must contain at most %d barcodes", MaxBarcodes))
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		field := fmt.Sprintf("%s[%d]", field, i)
+		normalized, ok := NormalizeBarcode(code)
+		if !ok {
+			v.Add(field, "must be a valid EAN-13 or UPC-A barcode")
+			continue
+		}
+		v.Check(!seen[normalized], field, "duplicate barcode")
+		seen[normalized] = true
+	}
+}
+
+// =====================================================
+// VARIANT (POST / PUT /product/{id}/variants)
+// =====================================================
+func Variant(pv *model.ProductVariant) error {
+	var v Validator
+
+	v.Check(len(pv.Attributes) > 0, "attributes", "cannot be empty")
+	v.Check(len(pv.Attributes) <= MaxVariantAttributes, "attributes", fmt.Sprintf("must contain at most %d attributes", MaxVariantAttributes))
+	for key, value := range pv.Attributes {
+		field := "attributes." + key
+		v.Required(field, key)
+		v.MaxLength(field, key, MaxAttributeLength)
+		v.Required(field, value)
+		v.MaxLength(field, value, MaxAttributeLength)
+	}
+
+	v.Min("harga", pv.Harga, 0)
+	v.Min("stok", pv.Stok, 0)
+	sku(&v, "sku", pv.SKU)
+
+	return v.Err()
+}
+
+func sku(v *Validator, field, value string) {
+	if value == "" {
+		return
+	}
+	v.MaxLength(field, value, MaxSKULength)
+	v.Check(skuPattern.MatchString(value), field, "may only contain letters, digits, '.', '_' and '-'")
+}
+
+// =====================================================
+// OUTLET (POST / PUT /outlets)
+// =====================================================
+func Outlet(o *model.Outlet) error {
+	var v Validator
+
+	v.Required("name", o.Name)
+	v.MaxLength("name", o.Name, MaxNameLength)
+	v.MaxLength("address", o.Address, MaxDescriptionLength)
+
+	return v.Err()
+}
+
+// =====================================================
+// STOCK TRANSFER (POST /transfers)
+// =====================================================
+func StockTransfer(t *model.StockTransfer) error {
+	var v Validator
+
+	v.Min("from_outlet_id", t.FromOutletID, 1)
+	v.Min("to_outlet_id", t.ToOutletID, 1)
+	v.Check(t.FromOutletID != t.ToOutletID, "to_outlet_id", "must be different from from_outlet_id")
+	v.MaxLength("note", t.Note, MaxDescriptionLength)
+
+	v.Check(len(t.Items) > 0, "items", "cannot be empty")
+	v.Check(len(t.Items) <= MaxTransferItems, "items", fmt.Sprintf("must contain at most %d items", MaxTransferItems))
+
+	for i, item := range t.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		v.Min(field+".product_id", item.ProductID, 1)
+		if item.VariantID != nil {
+			v.Min(field+".variant_id", *item.VariantID, 1)
+		}
+		v.Min(field+".quantity", item.Quantity, 1)
+		v.Max(field+".quantity", item.Quantity, MaxItemQuantity)
+	}
+
+	return v.Err()
+}
+
+// =====================================================
+// SUPPLIER (POST / PUT /suppliers)
+// =====================================================
+func Supplier(sp *model.Supplier) error {
+	var v Validator
+
+	v.Required("name", sp.Name)
+	v.MaxLength("name", sp.Name, MaxNameLength)
+	v.MaxLength("phone", sp.Phone, MaxPhoneLength)
+	v.MaxLength("email", sp.Email, MaxNameLength)
+	v.MaxLength("address", sp.Address, MaxDescriptionLength)
+
+	return v.Err()
+}
+
+// =====================================================
+// PURCHASE ORDER (POST / PUT /purchase-orders)
+// =====================================================
+func PurchaseOrder(po *model.PurchaseOrder) error {
+	var v Validator
+
+	v.Min("supplier_id", po.SupplierID, 1)
+	if po.OutletID != 0 {
+		v.Min("outlet_id", po.OutletID, 1)
+	}
+	v.MaxLength("note", po.Note, MaxDescriptionLength)
+
+	v.Check(len(po.Items) > 0, "items", "cannot be empty")
+	v.Check(len(po.Items) <= MaxPurchaseItems, "items", fmt.Sprintf("must contain at most %d items", MaxPurchaseItems))
+
+	for i, item := range po.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		v.Min(field+".product_id", item.ProductID, 1)
+		if item.VariantID != nil {
+			v.Min(field+".variant_id", *item.VariantID, 1)
+		}
+		v.Min(field+".quantity", item.Quantity, 1)
+		v.Max(field+".quantity", item.Quantity, MaxItemQuantity)
+		v.Min(field+".unit_cost", item.UnitCost, 0)
+	}
+
+	return v.Err()
+}
+
+// POST /purchase-orders/{id}/receive
+func ReceiveRequest(req *model.ReceiveRequest) error {
+	var v Validator
+
+	v.Check(len(req.Items) <= MaxPurchaseItems, "items", fmt.Sprintf("must contain at most %d items", MaxPurchaseItems))
+
+	for i, item := range req.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		v.Min(field+".item_id", item.ItemID, 1)
+		v.Min(field+".quantity", item.Quantity, 1)
+	}
+
+	return v.Err()
+}
+
+// =====================================================
+// CHECKOUT (POST /checkout)
+// =====================================================
+func CheckoutRequest(req *model.CheckoutRequest) error {
+	var v Validator
+
+	if req.OutletID != 0 {
+		v.Min("outlet_id", req.OutletID, 1)
+	}
+
+	v.Check(len(req.Items) > 0, "items", "cannot be empty")
+	v.Check(len(req.Items) <= MaxCheckoutItems, "items", fmt.Sprintf("must contain at most %d items", MaxCheckoutItems))
+
+	for i, item := range req.Items {
+		field := fmt.Sprintf("items[%d]", i)
+
+		// item dipilih lewat salah satu: product_id (+ variant_id), variant_id, barcode, atau sku
+		refs := 0
+		if item.ProductID != 0 || item.VariantID != 0 {
+			refs++
+		}
+		if item.ProductID != 0 {
+			v.Min(field+".product_id", item.ProductID, 1)
+		}
+		if item.VariantID != 0 {
+			v.Min(field+".variant_id", item.VariantID, 1)
+		}
+		if item.Barcode != "" {
+			refs++
+			_, ok := NormalizeBarcode(item.Barcode)
+			v.Check(ok, field+".barcode", "must be a valid EAN-13 or UPC-A barcode")
+		}
+		if item.SKU != "" {
+			refs++
+		}
+		v.Check(refs == 1, field, "exactly one of product_id/variant_id, barcode or sku is required")
+
+		v.Min(field+".quantity", item.Quantity, 1)
+		v.Max(field+".quantity", item.Quantity, MaxItemQuantity)
+	}
+
+	v.Check(len(req.Payments) <= MaxCheckoutPayments, "payments", fmt.Sprintf("must contain at most %d payments", MaxCheckoutPayments))
+
+	for i, p := range req.Payments {
+		field := fmt.Sprintf("payments[%d]", i)
+		v.Check(model.IsValidPaymentMethod(p.Method), field+".method", "must be cash, card, qris or ewallet")
+		v.Min(field+".amount", p.Amount, 1)
+	}
+
+	return v.Err()
+}
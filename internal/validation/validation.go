@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackyansen22/crud-category/internal/apperror"
+)
+
+// =====================================================
+// VALIDATOR
+// kumpulkan semua field error dulu, baru return sekaligus
+// → 400 VALIDATION_FAILED, details: [{field, message}]
+// =====================================================
+var ErrValidationFailed = apperror.Validation("VALIDATION_FAILED", "validation failed")
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Validator struct {
+	errors []FieldError
+}
+
+func (v *Validator) Add(field, message string) {
+	v.errors = append(v.errors, FieldError{Field: field, Message: message})
+}
+
+// Check: tambah error kalau ok false
+func (v *Validator) Check(ok bool, field, message string) {
+	if !ok {
+		v.Add(field, message)
+	}
+}
+
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// panjang dihitung per karakter (bukan byte), sesuai VARCHAR(n)
+func (v *Validator) MaxLength(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, field, fmt.Sprintf("must be at most %d characters", max))
+}
+
+func (v *Validator) Min(field string, value, min int) {
+	v.Check(value >= min, field, fmt.Sprintf("must be at least %d", min))
+}
+
+func (v *Validator) Max(field string, value, max int) {
+	v.Check(value <= max, field, fmt.Sprintf("must be at most %d", max))
+}
+
+func (v *Validator) Valid() bool {
+	return len(v.errors) == 0
+}
+
+// nil kalau tidak ada error
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return ErrValidationFailed.WithDetails(v.errors)
+}